@@ -0,0 +1,39 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"nmbr9/lib2"
+	"os"
+)
+
+// nmbr9 analyze -game game.json -format markdown
+func runAnalyze(args []string) {
+	cfg := lib2.DefaultAnalyzeConfig()
+	fs := flag.NewFlagSet("analyze", flag.ExitOnError)
+	path := fs.String("game", "game.json", "saved game to analyze, see -save when playing")
+	format := fs.String("format", "text", "report format: text or markdown")
+	fs.IntVar(&cfg.Steps, "steps", cfg.Steps, "cards searched for every move, counting the one placed")
+	fs.IntVar(&cfg.Endgame, "endgame", cfg.Endgame, endgameUsage)
+	fs.IntVar(&cfg.Top, "top", cfg.Top, "costliest mistakes to list")
+	fs.Parse(args)
+	if *format != "text" && *format != "markdown" {
+		fmt.Printf("unknown format: %v\n", *format)
+		return
+	}
+	rec, err := lib2.LoadGame(*path)
+	if err != nil {
+		fmt.Printf("error loading game: %v\n", err)
+		return
+	}
+	analysis, err := lib2.Analyze(rec, cfg)
+	if err != nil {
+		fmt.Printf("error analyzing game: %v\n", err)
+		return
+	}
+	if *format == "markdown" {
+		analysis.PrintMarkdown(os.Stdout)
+	} else {
+		analysis.PrintText(os.Stdout)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"nmbr9/lib2"
+	"os"
+	"strings"
+	"time"
+)
+
+// nmbr9 challenge -name ann
+// every card is placed with "row col", "hint" shows the engine's move, "auto" lets it place the card
+func runChallenge(args []string) {
+	fs := flag.NewFlagSet("challenge", flag.ExitOnError)
+	seed := fs.String("seed", lib2.DailySeed(time.Now()), "any string, everyone playing the same one gets the same cards (default: today's date)")
+	player := fs.String("name", "you", "name on the leaderboard")
+	name := fs.String("strategy", "lookahead:2", "engine that hints, places on auto and is scored against: "+lib2.StrategyNames)
+	path := fs.String("leaderboard", "leaderboard.json", "file the results are kept in")
+	maxNodes := fs.Int("max-nodes", 2000000, "positions the solver searches for the optimum, 0 for no limit")
+	save := fs.String("save", "", "write the game to this file after every card, for analyze")
+	fs.Parse(args)
+	strategy, err := lib2.StrategyByName(*name, 1)
+	if err != nil {
+		fmt.Printf("error picking strategy: %v\n", err)
+		return
+	}
+	lb, err := lib2.LoadLeaderboard(*path)
+	if err != nil {
+		fmt.Printf("error loading leaderboard: %v\n", err)
+		return
+	}
+	ch := lib2.NewChallenge(*seed)
+	ch.Strategy = *name
+	board := lib2.NewBoard()
+	helped := 0
+	in := bufio.NewScanner(os.Stdin)
+	for k, num := range ch.Cards {
+		if err := board.Discard(num); err == nil {
+			fmt.Printf("card %v/%v: %v fits nowhere\n", k+1, len(ch.Cards), num)
+			continue
+		}
+		for {
+			fmt.Printf("card %v/%v: %v, where does it go (row col, hint, auto): ", k+1, len(ch.Cards), num)
+			if !in.Scan() {
+				fmt.Println("challenge abandoned")
+				return
+			}
+			line := strings.TrimSpace(in.Text())
+			if line == "hint" || line == "auto" {
+				move, err := strategy.ChooseMove(board.Clone(), num, remainingAfter(board, num))
+				if err != nil {
+					fmt.Printf("error asking the engine: %v\n", err)
+					continue
+				}
+				helped++
+				if line == "hint" {
+					fmt.Printf("the engine would play (%v, %v, %v)\n", move.Row, move.Col, move.Level)
+					continue
+				}
+				if err := board.ApplyMove(move); err != nil {
+					fmt.Printf("error applying move: %v\n", err)
+					continue
+				}
+				break
+			}
+			var row, col int
+			if _, err := fmt.Sscanf(line, "%d %d", &row, &col); err != nil {
+				fmt.Printf("error reading move: %v\n", err)
+				continue
+			}
+			if err := board.ApplyMove(lib2.Move{Num: num, Row: row, Col: col}); err != nil {
+				fmt.Printf("error applying move: %v\n", err)
+				continue
+			}
+			break
+		}
+		board.PrintIso()
+		fmt.Printf("score %v\n", board.Score())
+		if *save != "" {
+			if err := board.SaveGame(*save); err != nil {
+				fmt.Printf("error saving game: %v\n", err)
+			}
+		}
+	}
+	fmt.Println("scoring the engine and searching for the optimum...")
+	res, err := ch.Result(*player, board, helped, lib2.SolveConfig{MaxNodes: *maxNodes})
+	if err != nil {
+		fmt.Printf("error scoring challenge: %v\n", err)
+		return
+	}
+	res.Print(os.Stdout)
+	lb.Add(res)
+	if err := lb.Save(*path); err != nil {
+		fmt.Printf("error saving leaderboard: %v\n", err)
+	}
+	lb.Print(os.Stdout, ch.Seed)
+}
+
+// the deck once num has been drawn
+func remainingAfter(board *lib2.Board, num int) []int {
+	remaining := board.Remaining()
+	remaining[num]--
+	return remaining
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"nmbr9/lib2"
+	"os"
+)
+
+// nmbr9 coach -steps 2
+// every line is the card drawn and where it goes: num row col, followed by the
+// orientation when the pieces may be turned
+func runCoach(args []string) {
+	fs := flag.NewFlagSet("coach", flag.ExitOnError)
+	steps := fs.Int("steps", 2, "cards the engine searches for every move, counting the one placed")
+	endgame := fs.Int("endgame", 0, endgameUsage)
+	iso := fs.Bool("iso", false, "draw the board as one isometric picture instead of one grid per level")
+	save := fs.String("save", "", "write the game to this file after every card, for analyze")
+	rules := addRuleFlags(fs)
+	fs.Parse(args)
+	board, err := rules.board()
+	if err != nil {
+		fmt.Printf("error setting up board: %v\n", err)
+		return
+	}
+	board.SetEndgame(*endgame)
+	lost := 0.0
+	in := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Print("enter a number and where it goes (num row col [orientation]): ")
+		if !in.Scan() {
+			break
+		}
+		var m lib2.Move
+		if n, _ := fmt.Sscan(in.Text(), &m.Num, &m.Row, &m.Col, &m.Orient); n < 3 {
+			fmt.Printf("error scanning input: %q\n", in.Text())
+			break
+		}
+		if err := board.Discard(m.Num); err == nil {
+			fmt.Printf("%v fits nowhere, the card is lost\n", m.Num)
+		} else if advice, err := board.Advise(m, *steps); err != nil {
+			fmt.Printf("error checking move: %v\n", err)
+			continue
+		} else if err := board.ApplyMove(advice.Played); err != nil {
+			fmt.Printf("error applying move: %v\n", err)
+			continue
+		} else {
+			if *iso {
+				board.PrintIso()
+			} else {
+				board.PrintOverlays(false)
+			}
+			lost += advice.Regret
+			fmt.Println(advice)
+		}
+		fmt.Printf("score %v  expected points lost so far %.2f\n", board.Score(), lost)
+		if *save != "" {
+			if err := board.SaveGame(*save); err != nil {
+				fmt.Printf("error saving game: %v\n", err)
+			}
+		}
+		if board.GameOver() {
+			fmt.Printf("game over, final score %v, expected points lost %.2f\n", board.Score(), lost)
+			break
+		}
+	}
+}
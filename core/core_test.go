@@ -0,0 +1,506 @@
+package core_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"nmbr9/core"
+	"nmbr9/lib"
+	"nmbr9/lib2"
+)
+
+// every backend runs every test
+var backends = []struct {
+	name    string
+	storage func(rows, cols int) core.Storage
+}{
+	{"lib", lib.NewStorage},
+	{"lib2", lib2.NewStorage},
+}
+
+// the numbers 0-9 as drawn, scoring num*level
+var digits = &core.Search{
+	Orients: func(num int) []int { return []int{0} },
+	Piece:   piece,
+	Score:   func(num int, level int8) int { return num * int(level) },
+}
+
+func piece(num, o int) *core.Piece {
+	return &core.Piece{Rows: 4, Cols: 3, Tiles: lib2.NUMBER[num]}
+}
+
+// puts num at (row,col) on its own layer and flattens it onto flat at level
+func put(storage func(int, int) core.Storage, flat core.Storage, num, row, col int, level int8) {
+	layer := storage(flat.Size())
+	core.Put(layer, piece(num, 0), row, col)
+	flat.Flatten(layer, level)
+}
+
+func cells(s core.Storage) []int8 {
+	R, _ := s.Size()
+	cells := []int8{}
+	for r := 0; r < R; r++ {
+		cells = append(cells, s.Row(r)...)
+	}
+	return cells
+}
+
+func show(s core.Storage) {
+	R, C := s.Size()
+	for r := 0; r < R; r++ {
+		for c := 0; c < C; c++ {
+			if v := s.Get(r, c); v == core.EMPTY {
+				fmt.Print(".")
+			} else {
+				fmt.Print(v)
+			}
+		}
+		fmt.Println()
+	}
+}
+
+func TestBestMove1Setup2Best(t *testing.T) {
+	tests := []struct {
+		setupNum       int
+		setupMove      [2]int
+		nextNum0       int
+		wantNextMove0  [2]int
+		wantNextLevel0 int8
+		nextNum1       int
+		wantNextMove1  [2]int
+		wantNextLevel1 int8
+	}{
+		/*
+			setup:    next:     next:
+			.999...   .988...   .98866.
+			.999...   .988...   .9886..
+			.99....   .88....   .88.666
+			.99....   .88....   .88.666
+		*/
+		{
+			setupNum: 9, setupMove: [2]int{0, 1},
+			nextNum0: 8, wantNextMove0: [2]int{0, 1}, wantNextLevel0: 1,
+			nextNum1: 6, wantNextMove1: [2]int{0, 4}, wantNextLevel1: 0,
+		},
+		/*
+			setup:    next:     next:
+			..22...   ..2244.   ..6644.
+			..22...   ..224..   ..624..
+			.22....   .22444.   .26664.
+			.222...   .22244.   .26664.
+		*/
+		{
+			setupNum: 2, setupMove: [2]int{0, 1},
+			nextNum0: 4, wantNextMove0: [2]int{0, 3}, wantNextLevel0: 0,
+			nextNum1: 6, wantNextMove1: [2]int{0, 2}, wantNextLevel1: 1,
+		},
+	}
+	for _, b := range backends {
+		for _, tt := range tests {
+			R, C := 4, 7
+			flat := b.storage(R, C)
+			put(b.storage, flat, tt.setupNum, tt.setupMove[0], tt.setupMove[1], 0)
+			steps := 1
+			deck := []int{1, 1, 1, 1, 1, 1, 1, 1, 1, 1}
+			// find best move 0
+			seen := make([]int, 10)
+			m, _, err := digits.BestMove(flat, seen, deck, tt.nextNum0, steps)
+			if err != nil {
+				t.Fatalf("%v: err finding best move: %v", b.name, err)
+			}
+			if best, want := [2]int{m.Row, m.Col}, tt.wantNextMove0; best != want {
+				t.Fatalf("%v: best move: want:%v != got:%v", b.name, want, best)
+			}
+			if best, want := m.Level, tt.wantNextLevel0; best != want {
+				t.Fatalf("%v: best level: want:%v != got:%v", b.name, want, best)
+			}
+			// apply best move 0
+			put(b.storage, flat, tt.nextNum0, m.Row, m.Col, m.Level)
+			// find best move 1
+			seen = make([]int, 10)
+			m, _, err = digits.BestMove(flat, seen, deck, tt.nextNum1, steps)
+			if err != nil {
+				t.Fatalf("%v: err finding best move: %v", b.name, err)
+			}
+			if best, want := [2]int{m.Row, m.Col}, tt.wantNextMove1; best != want {
+				t.Fatalf("%v: best move: want:%v != got:%v", b.name, want, best)
+			}
+			if best, want := m.Level, tt.wantNextLevel1; best != want {
+				t.Fatalf("%v: best level: want:%v != got:%v", b.name, want, best)
+			}
+		}
+	}
+}
+
+func TestGreedy1Setup2Best(t *testing.T) {
+	tests := []struct {
+		setupNum       int
+		setupMove      [2]int
+		nextNum0       int
+		wantNextMove0  [2]int
+		wantNextLevel0 int8
+		nextNum1       int
+		wantNextMove1  [2]int
+		wantNextLevel1 int8
+	}{
+		/*
+			setup:    next:     next:
+			.999...   .988...   .98866.
+			.999...   .988...   .9886..
+			.99....   .88....   .88.666
+			.99....   .88....   .88.666
+		*/
+		{
+			setupNum: 9, setupMove: [2]int{0, 1},
+			nextNum0: 8, wantNextMove0: [2]int{0, 1}, wantNextLevel0: 1,
+			nextNum1: 6, wantNextMove1: [2]int{0, 4}, wantNextLevel1: 0,
+		},
+		/*
+			setup:    next:     next:
+			..22...   ..2244.   ..6644.
+			..22...   ..224..   ..624..
+			.22....   .22444.   .26664.
+			.222...   .22244.   .26664.
+		*/
+		{
+			setupNum: 2, setupMove: [2]int{0, 1},
+			nextNum0: 4, wantNextMove0: [2]int{0, 3}, wantNextLevel0: 0,
+			nextNum1: 6, wantNextMove1: [2]int{0, 2}, wantNextLevel1: 1,
+		},
+	}
+	for _, b := range backends {
+		for _, tt := range tests {
+			R, C := 4, 7
+			flat := b.storage(R, C)
+			put(b.storage, flat, tt.setupNum, tt.setupMove[0], tt.setupMove[1], 0)
+			// find best move 0
+			m := digits.Greedy(flat, tt.nextNum0)
+			if best, want := [2]int{m.Row, m.Col}, tt.wantNextMove0; best != want {
+				t.Fatalf("%v: best move: want:%v != got:%v", b.name, want, best)
+			}
+			if best, want := m.Level, tt.wantNextLevel0; best != want {
+				t.Fatalf("%v: best level: want:%v != got:%v", b.name, want, best)
+			}
+			// apply best move 0
+			put(b.storage, flat, tt.nextNum0, m.Row, m.Col, m.Level)
+			// find best move 1
+			m = digits.Greedy(flat, tt.nextNum1)
+			if best, want := [2]int{m.Row, m.Col}, tt.wantNextMove1; best != want {
+				t.Fatalf("%v: best move: want:%v != got:%v", b.name, want, best)
+			}
+			if best, want := m.Level, tt.wantNextLevel1; best != want {
+				t.Fatalf("%v: best level: want:%v != got:%v", b.name, want, best)
+			}
+		}
+	}
+}
+
+func TestGreedy2Setups(t *testing.T) {
+	tests := []struct {
+		setupNum    int
+		setupMove   [2]int
+		setupLevel  int8
+		setupNum1   int
+		setupMove1  [2]int
+		setupLevel1 int8
+		nextNum     int
+		nextMove    [2]int
+	}{
+		/*
+			setup:    next:     next:	  best: 0,1
+			.999...   .99988.   .66988.
+			.999...   .99988.   .69988.
+			.99....   .9988..   .6668..
+			.99....   .9988..   .6668..
+		*/
+		{setupNum: 9, setupMove: [2]int{0, 1}, setupLevel: 1, setupNum1: 8, setupMove1: [2]int{0, 3}, setupLevel1: 1, nextNum: 6, nextMove: [2]int{0, 1}},
+		/*
+			setup:    next:     next:     best: 0,4
+			.000...   .011...   .011.22
+			.0.0...   .0.1...   .0.1.22
+			.0.0...   .0.1...   .0.122.
+			.000...   .001...   .001222
+		*/
+		{setupNum: 0, setupMove: [2]int{0, 1}, setupLevel: 1, setupNum1: 1, setupMove1: [2]int{0, 2}, setupLevel1: 2, nextNum: 2, nextMove: [2]int{0, 4}},
+		/*
+			setup:    next:     next:	  best: 0,0
+			...999.   ...988.   777988.
+			...999.   ...988.   .7.988.
+			...99..   ...88..   77.88..
+			...99..   ...88..   7..88..
+		*/
+		{setupNum: 9, setupMove: [2]int{0, 3}, setupLevel: 1, setupNum1: 8, setupMove1: [2]int{0, 3}, setupLevel1: 2, nextNum: 7, nextMove: [2]int{0, 0}},
+	}
+	for _, b := range backends {
+		for _, tt := range tests {
+			R, C := 4, 7
+			flat := b.storage(R, C)
+			put(b.storage, flat, tt.setupNum, tt.setupMove[0], tt.setupMove[1], tt.setupLevel)
+			put(b.storage, flat, tt.setupNum1, tt.setupMove1[0], tt.setupMove1[1], tt.setupLevel1)
+			m := digits.Greedy(flat, tt.nextNum)
+			if best, want := [2]int{m.Row, m.Col}, tt.nextMove; best != want {
+				t.Fatalf("%v: want not equal to got: %v != %v", b.name, want, best)
+			}
+		}
+	}
+}
+
+func TestGreedy1Setup(t *testing.T) {
+	tests := []struct {
+		setupNum  int
+		setupMove [2]int
+		nextNum   int
+		nextMove  [2]int
+	}{
+		/*
+			setup:    next:    best: 0,3
+			..000..   ..011..
+			..0.0..   ..0.1..
+			..0.0..   ..0.1..
+			..000..   ..001..
+		*/
+		{setupNum: 0, setupMove: [2]int{0, 2}, nextNum: 1, nextMove: [2]int{0, 3}},
+		/*
+			setup:    next:    best: 0,3
+			...999.   ....88.
+			...999.   ....88.
+			...99..   ...88..
+			...99..   ...88..
+		*/
+		{setupNum: 9, setupMove: [2]int{0, 3}, nextNum: 8, nextMove: [2]int{0, 3}},
+		/*
+			setup:    next:    best: 0,3
+			...999.   ...11..
+			...999.   ....1..
+			...99..   ....1..
+			...99..   ....1..
+		*/
+		{setupNum: 9, setupMove: [2]int{0, 3}, nextNum: 1, nextMove: [2]int{0, 3}},
+	}
+	for _, b := range backends {
+		for _, tt := range tests {
+			R, C := 4, 7
+			flat := b.storage(R, C)
+			put(b.storage, flat, tt.setupNum, tt.setupMove[0], tt.setupMove[1], 1)
+			m := digits.Greedy(flat, tt.nextNum)
+			if best, want := [2]int{m.Row, m.Col}, tt.nextMove; best != want {
+				t.Fatalf("%v: want not equal to got: %v != %v", b.name, want, best)
+			}
+		}
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		setupNum  int
+		setupMove [2]int
+		nextNum   int
+		nextMove  [2]int
+		wantValid bool
+		wantLevel int8
+	}{
+		/*
+			setup:    next:
+			.999...   .9988..
+			.999...   .9988..
+			.99....   .988...
+			.99....   .988...
+		*/
+		{setupNum: 9, setupMove: [2]int{0, 1}, nextNum: 8, nextMove: [2]int{0, 2}, wantValid: false, wantLevel: 0},
+		/*
+			setup:    next:
+			.999...   .99988.
+			.999...   .99988.
+			.99....   .9988..
+			.99....   .9988..
+		*/
+		{setupNum: 9, setupMove: [2]int{0, 1}, nextNum: 8, nextMove: [2]int{0, 3}, wantValid: true, wantLevel: 0},
+		/*
+			setup:    next:
+			.999...   .988...
+			.999...   .988...
+			.99....   .88....
+			.99....   .88....
+		*/
+		{setupNum: 9, setupMove: [2]int{0, 1}, nextNum: 8, nextMove: [2]int{0, 1}, wantValid: true, wantLevel: 1},
+	}
+	for _, b := range backends {
+		for _, tt := range tests {
+			R, C := 4, 7
+			flat := b.storage(R, C)
+			put(b.storage, flat, tt.setupNum, tt.setupMove[0], tt.setupMove[1], 0)
+			valid, level := digits.Valid(flat, tt.nextNum, 0, tt.nextMove[0], tt.nextMove[1])
+			if valid != tt.wantValid {
+				t.Fatalf("%v: valid: want not equal to got: %v != %v", b.name, tt.wantValid, valid)
+			}
+			if level != tt.wantLevel {
+				t.Fatalf("%v: level: want not equal to got: %v != %v", b.name, tt.wantLevel, level)
+			}
+		}
+	}
+}
+
+func TestInBounds(t *testing.T) {
+	for _, b := range backends {
+		flat := b.storage(4, 5)
+		/*
+			should be in bounds:
+			01110
+			01010
+			01010
+			01110
+		*/
+		if got := core.InBounds(flat, piece(0, 0), 0, 1); !got {
+			t.Fatalf("%v: want not equal to got: %v != %v", b.name, true, got)
+		}
+		/*
+			should be out of bounds:
+			00000
+			01110
+			01010
+			01010
+		*/
+		if got := core.InBounds(flat, piece(0, 0), 1, 1); got {
+			t.Fatalf("%v: want not equal to got: %v != %v", b.name, false, got)
+		}
+		/*
+			should be out of bounds:
+			00011
+			00010
+			00010
+			00011
+		*/
+		if got := core.InBounds(flat, piece(0, 0), 0, 3); got {
+			t.Fatalf("%v: want not equal to got: %v != %v", b.name, false, got)
+		}
+		// anchors left of or above the board are out of bounds too
+		if got := core.InBounds(flat, piece(0, 0), 0, -1); got {
+			t.Fatalf("%v: want not equal to got: %v != %v", b.name, false, got)
+		}
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	for _, b := range backends {
+		R, C := 4, 5
+		layer := b.storage(R, C)
+		core.Put(layer, piece(0, 0), 0, 1)
+		flat := b.storage(R, C)
+		flat.Flatten(layer, 0)
+		/*
+			should be:
+			.000.
+			.0.0.
+			.0.0.
+			.000.
+		*/
+		want := []int8{-1, 0, 0, 0, -1, -1, 0, -1, 0, -1, -1, 0, -1, 0, -1, -1, 0, 0, 0, -1}
+		if !reflect.DeepEqual(cells(flat), want) {
+			fmt.Println("got:")
+			show(flat)
+			t.Fatalf("%v: want not equal to got", b.name)
+		}
+		// add another layer and flatten it
+		layer = b.storage(R, C)
+		core.Put(layer, piece(1, 0), 0, 2)
+		flat.Flatten(layer, 1)
+		/*
+			should be:
+			.011.
+			.0.1.
+			.0.1.
+			.001.
+		*/
+		want = []int8{-1, 0, 1, 1, -1, -1, 0, -1, 1, -1, -1, 0, -1, 1, -1, -1, 0, 0, 1, -1}
+		if !reflect.DeepEqual(cells(flat), want) {
+			fmt.Println("got:")
+			show(flat)
+			t.Fatalf("%v: want not equal to got", b.name)
+		}
+	}
+}
+
+func TestPutBounds(t *testing.T) {
+	for _, b := range backends {
+		layer := b.storage(12, 10)
+		if tlR, _, brR, _ := layer.Bounds(); tlR <= brR {
+			t.Fatalf("%v: empty layer has bounds: %v > %v", b.name, tlR, brR)
+		}
+		/*
+			should be:
+			.......  .......
+			.......  .......
+			...22..  ...2244
+			...22..  ...224.
+			..22...  ..22444
+			..222..  ..22244
+		*/
+		core.Put(layer, piece(2, 0), 2, 2)
+		if tlR, tlC, brR, brC := layer.Bounds(); [4]int{tlR, tlC, brR, brC} != [4]int{2, 2, 5, 4} {
+			show(layer)
+			t.Fatalf("%v: bounding box: want:%v != got:%v", b.name, []int{2, 2, 5, 4}, []int{tlR, tlC, brR, brC})
+		}
+		core.Put(layer, piece(4, 0), 2, 4)
+		if tlR, tlC, brR, brC := layer.Bounds(); [4]int{tlR, tlC, brR, brC} != [4]int{2, 2, 5, 6} {
+			show(layer)
+			t.Fatalf("%v: bounding box: want:%v != got:%v", b.name, []int{2, 2, 5, 6}, []int{tlR, tlC, brR, brC})
+		}
+	}
+}
+
+func TestPutSkipEmpty(t *testing.T) {
+	for _, b := range backends {
+		layer := b.storage(4, 5)
+		core.Put(layer, piece(2, 0), 0, 0)
+		core.Put(layer, piece(4, 0), 0, 2)
+		/*
+			should be:
+			.22..  .2244
+			.22..  .224.
+			22...  22444
+			222..  22244
+		*/
+		want := []int8{-1, 2, 2, 4, 4, -1, 2, 2, 4, -1, 2, 2, 4, 4, 4, 2, 2, 2, 4, 4}
+		if !reflect.DeepEqual(cells(layer), want) {
+			fmt.Println("got:")
+			show(layer)
+			t.Fatalf("%v: want not equal to got", b.name)
+		}
+	}
+}
+
+func TestPut(t *testing.T) {
+	for _, b := range backends {
+		layer := b.storage(4, 5)
+		core.Put(layer, piece(0, 0), 0, 1)
+		/*
+			should be:
+			.000.
+			.0.0.
+			.0.0.
+			.000.
+		*/
+		want := []int8{-1, 0, 0, 0, -1, -1, 0, -1, 0, -1, -1, 0, -1, 0, -1, -1, 0, 0, 0, -1}
+		if !reflect.DeepEqual(cells(layer), want) {
+			fmt.Println("got:")
+			show(layer)
+			t.Fatalf("%v: want not equal to got", b.name)
+		}
+	}
+}
+
+func TestCopy(t *testing.T) {
+	for _, b := range backends {
+		flat := b.storage(4, 7)
+		put(b.storage, flat, 9, 0, 1, 0)
+		copied := flat.Copy()
+		put(b.storage, flat, 8, 0, 1, 1)
+		if reflect.DeepEqual(cells(flat), cells(copied)) {
+			t.Fatalf("%v: copy changed with the original", b.name)
+		}
+		if got := copied.Get(0, 2); got != 0 {
+			t.Fatalf("%v: copied cell: want:0 != got:%v", b.name, got)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package core
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+)
+
+/*
+
+	diff = random legal games played on every backend at once, after every card the
+		   backends have to agree on the position
+			flat		the same level in every cell
+			legal		the same placements for the drawn card
+			best		the same choice and score from the lookahead at every depth
+	repro = the position a difference was found at, shrunk by dropping every placement
+			that isn't needed to show it, the first one always stays since it goes in
+			the middle
+	game i is played with seed+i like the simulations
+
+*/
+
+// a board the diff harness can drive, every storage backend has one
+type Backend interface {
+	// the first move goes in the middle of the board wherever m says
+	ApplyMove(m Move) error
+	LegalMoves(num int) []Move
+	Flat() Storage
+	// what the lookahead picks for num and its score, nothing is placed
+	BestMove(num, steps int) (Move, int, error)
+}
+
+// an empty board with copies of every number on rows x cols
+type NewBackend func(rows, cols, copies int) Backend
+
+type DiffConfig struct {
+	Games  int
+	Seed   int64
+	R      int
+	C      int
+	Nums   int   // the numbers 0 to Nums-1 are played
+	Copies int   // copies of every number
+	Depths []int // lookahead steps compared after every card
+}
+
+func DefaultDiffConfig() DiffConfig {
+	return DiffConfig{Games: 10, Seed: 1, R: 12, C: 12, Nums: 10, Copies: 2, Depths: []int{1, 2}}
+}
+
+// a position the backends disagree on
+type Mismatch struct {
+	Check    string // flat, legal, best or apply
+	Num      int    // the card checked, not for flat
+	Steps    int    // the lookahead depth, best only
+	Moves    []Move // the position, played from an empty board
+	Backends []string
+	Got      []string // what every backend said, in the order of Backends
+}
+
+func (m *Mismatch) Error() string {
+	return fmt.Sprintf("backends disagree on %v", m.Check)
+}
+
+func (m *Mismatch) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%v after %v moves", m.Error(), len(m.Moves))
+	if m.Check != "flat" {
+		fmt.Fprintf(&b, ", num:%v", m.Num)
+	}
+	if m.Check == "best" {
+		fmt.Fprintf(&b, " steps:%v", m.Steps)
+	}
+	b.WriteString("\nmoves:\n")
+	for _, mv := range m.Moves {
+		fmt.Fprintf(&b, "\t%+v\n", mv)
+	}
+	for i, name := range m.Backends {
+		fmt.Fprintf(&b, "%v:\n%v\n", name, m.Got[i])
+	}
+	return b.String()
+}
+
+// plays cfg.Games random games on every backend and returns the first position they
+// disagree on, shrunk, or nil if they never do
+func Diff(cfg DiffConfig, backends map[string]NewBackend) (*Mismatch, error) {
+	if len(backends) < 2 {
+		return nil, fmt.Errorf("diff needs at least 2 backends, got %v", len(backends))
+	}
+	if cfg.Nums < 1 || cfg.Copies < 1 {
+		return nil, fmt.Errorf("diff needs numbers and copies, got nums:%v copies:%v", cfg.Nums, cfg.Copies)
+	}
+	for _, steps := range cfg.Depths {
+		if steps < 1 {
+			return nil, fmt.Errorf("depth:%v must be at least 1", steps)
+		}
+	}
+	d := &differ{cfg: cfg, backends: backends}
+	for name := range backends {
+		d.names = append(d.names, name)
+	}
+	sort.Strings(d.names)
+	for g := 0; g < cfg.Games; g++ {
+		if m := d.game(rand.New(rand.NewSource(cfg.Seed + int64(g)))); m != nil {
+			return d.shrink(m), nil
+		}
+	}
+	return nil, nil
+}
+
+type differ struct {
+	cfg      DiffConfig
+	backends map[string]NewBackend
+	names    []string
+}
+
+func (d *differ) game(rng *rand.Rand) *Mismatch {
+	cards := []int{}
+	for num := 0; num < d.cfg.Nums; num++ {
+		for i := 0; i < d.cfg.Copies; i++ {
+			cards = append(cards, num)
+		}
+	}
+	rng.Shuffle(len(cards), func(i, j int) { cards[i], cards[j] = cards[j], cards[i] })
+	moves := []Move{}
+	for _, num := range cards {
+		if m := d.check(moves, num, "", 0); m != nil {
+			return m
+		}
+		// every backend agrees on the legal moves, so any of them can pick
+		boards, _ := d.replay(moves)
+		legal := boards[0].LegalMoves(num)
+		if len(legal) == 0 {
+			// the card fits nowhere and is put aside
+			continue
+		}
+		moves = append(moves, legal[rng.Intn(len(legal))])
+	}
+	return d.check(moves, -1, "", 0)
+}
+
+// fresh boards with moves played on them, a mismatch if they don't all take them
+// the same way
+func (d *differ) replay(moves []Move) ([]Backend, *Mismatch) {
+	boards := make([]Backend, len(d.names))
+	for i, name := range d.names {
+		boards[i] = d.backends[name](d.cfg.R, d.cfg.C, d.cfg.Copies)
+	}
+	for n, m := range moves {
+		// backends word their errors differently, only taking the move or not counts
+		took := make([]string, len(boards))
+		got := make([]string, len(boards))
+		for i, board := range boards {
+			took[i], got[i] = "ok", "ok"
+			if err := board.ApplyMove(m); err != nil {
+				took[i], got[i] = "rejected", err.Error()
+			}
+		}
+		if !allSame(took) {
+			return nil, &Mismatch{Check: "apply", Num: m.Num, Moves: slices.Clone(moves[:n+1]), Backends: d.names, Got: got}
+		}
+		if took[0] != "ok" {
+			return nil, nil
+		}
+	}
+	return boards, nil
+}
+
+// compares the backends after moves and with num drawn next, num is -1 to only compare
+// the flats
+// only looks at check and steps when check is set, to see if a smaller position still
+// shows the same mismatch
+func (d *differ) check(moves []Move, num int, check string, steps int) *Mismatch {
+	boards, m := d.replay(moves)
+	if m != nil || boards == nil {
+		return m
+	}
+	mismatch := func(check string, steps int, got []string) *Mismatch {
+		if allSame(got) {
+			return nil
+		}
+		return &Mismatch{Check: check, Num: num, Steps: steps, Moves: slices.Clone(moves), Backends: d.names, Got: got}
+	}
+	got := make([]string, len(boards))
+	if check == "" || check == "flat" {
+		for i, board := range boards {
+			got[i] = cellString(board.Flat())
+		}
+		if m := mismatch("flat", 0, got); m != nil || num < 0 {
+			return m
+		}
+	}
+	if check == "" || check == "legal" {
+		for i, board := range boards {
+			got[i] = movesString(board.LegalMoves(num))
+		}
+		if m := mismatch("legal", 0, got); m != nil {
+			return m
+		}
+	}
+	if len(moves) == 0 {
+		// the first number goes in the middle, nothing to search
+		return nil
+	}
+	for _, s := range d.cfg.Depths {
+		if check != "" && (check != "best" || s != steps) {
+			continue
+		}
+		for i, board := range boards {
+			move, score, err := board.BestMove(num, s)
+			got[i] = fmt.Sprintf("%+v score:%v err:%v", move, score, err)
+		}
+		if m := mismatch("best", s, got); m != nil {
+			return m
+		}
+	}
+	return nil
+}
+
+// drops every move after the first that m can do without
+func (d *differ) shrink(m *Mismatch) *Mismatch {
+	for shrunk := true; shrunk; {
+		shrunk = false
+		for i := len(m.Moves) - 1; i >= 1; i-- {
+			if i >= len(m.Moves) || (m.Check == "apply" && i == len(m.Moves)-1) {
+				// the move the backends disagree on
+				continue
+			}
+			moves := slices.Delete(slices.Clone(m.Moves), i, i+1)
+			var smaller *Mismatch
+			if m.Check == "apply" {
+				_, smaller = d.replay(moves)
+			} else {
+				smaller = d.check(moves, m.Num, m.Check, m.Steps)
+			}
+			if smaller != nil && smaller.Check == m.Check {
+				m, shrunk = smaller, true
+			}
+		}
+	}
+	return m
+}
+
+func allSame(got []string) bool {
+	for _, g := range got[1:] {
+		if g != got[0] {
+			return false
+		}
+	}
+	return true
+}
+
+func cellString(s Storage) string {
+	var b strings.Builder
+	R, C := s.Size()
+	for r := 0; r < R; r++ {
+		for c := 0; c < C; c++ {
+			if v := s.Get(r, c); v == EMPTY {
+				b.WriteByte('.')
+			} else {
+				fmt.Fprint(&b, v)
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// moves in a fixed order so backends that scan differently still compare equal
+func movesString(moves []Move) string {
+	moves = slices.Clone(moves)
+	slices.SortFunc(moves, func(a, b Move) int {
+		if a.Orient != b.Orient {
+			return a.Orient - b.Orient
+		}
+		if a.Row != b.Row {
+			return a.Row - b.Row
+		}
+		return a.Col - b.Col
+	})
+	return fmt.Sprint(moves)
+}
@@ -0,0 +1,71 @@
+package core_test
+
+import (
+	"testing"
+
+	"nmbr9/core"
+	"nmbr9/lib"
+	"nmbr9/lib2"
+)
+
+func TestDiffBackendsAgree(t *testing.T) {
+	cfg := core.DefaultDiffConfig()
+	cfg.Games = 3
+	m, err := core.Diff(cfg, map[string]core.NewBackend{"lib": lib.NewBackend, "lib2": lib2.NewBackend})
+	if err != nil {
+		t.Fatalf("err diffing: %v", err)
+	}
+	if m != nil {
+		t.Fatalf("%v", m.String())
+	}
+}
+
+// lib2 with a bug: nothing can be stacked
+type flatOnly struct {
+	core.Backend
+}
+
+func (b flatOnly) LegalMoves(num int) []core.Move {
+	moves := []core.Move{}
+	for _, m := range b.Backend.LegalMoves(num) {
+		if m.Level == 0 {
+			moves = append(moves, m)
+		}
+	}
+	return moves
+}
+
+func TestDiffShrinks(t *testing.T) {
+	cfg := core.DefaultDiffConfig()
+	cfg.Games = 1
+	cfg.Depths = nil
+	broken := func(rows, cols, copies int) core.Backend {
+		return flatOnly{lib2.NewBackend(rows, cols, copies)}
+	}
+	m, err := core.Diff(cfg, map[string]core.NewBackend{"lib": lib.NewBackend, "broken": broken})
+	if err != nil {
+		t.Fatalf("err diffing: %v", err)
+	}
+	if m == nil {
+		t.Fatalf("the broken backend should disagree")
+	}
+	// a number only stacks across 2 others
+	if m.Check != "legal" || len(m.Moves) != 2 {
+		t.Fatalf("want legal after 2 moves, got:\n%v", m.String())
+	}
+	if m.Backends[0] != "broken" || m.Backends[1] != "lib" {
+		t.Fatalf("backends: %v", m.Backends)
+	}
+}
+
+func TestDiffConfig(t *testing.T) {
+	backends := map[string]core.NewBackend{"lib": lib.NewBackend, "lib2": lib2.NewBackend}
+	cfg := core.DefaultDiffConfig()
+	cfg.Depths = []int{0}
+	if _, err := core.Diff(cfg, backends); err == nil {
+		t.Fatalf("depth 0 should be an error")
+	}
+	if _, err := core.Diff(core.DefaultDiffConfig(), map[string]core.NewBackend{"lib": lib.NewBackend}); err == nil {
+		t.Fatalf("1 backend should be an error")
+	}
+}
@@ -0,0 +1,176 @@
+package core
+
+/*
+
+	placement rules, every one reads the flat only
+		in bounds		every tile of the piece is on the board
+		same level		every tile lies on the same level, the piece goes one above it
+		touching		on level 0 the piece touches a tile next to it
+		every level		with EveryLevel, a piece above the bottom also touches a tile on
+						its own level once that level has one
+		bottom only		with BottomOnly nothing is stacked
+
+*/
+
+// the switches of the placement rules, the zero value is the standard game
+type Placement struct {
+	EveryLevel bool // pieces above the bottom touch a tile on their level once it has one
+	BottomOnly bool // every piece goes on level 0
+}
+
+// check if p can be placed at (row,col) using flat, and the level it goes on
+func Valid(flat Storage, p *Piece, row, col int, rules Placement) (bool, int8) {
+	R, C := flat.Size()
+	if !inBounds(R, C, p, row, col) {
+		return false, 0
+	}
+	return valid(flat, R, C, p, row, col, rules)
+}
+
+// Valid for an anchor already known to be in bounds, the searches only try those
+func valid(flat Storage, R, C int, p *Piece, row, col int, rules Placement) (bool, int8) {
+	// validity requires that every non-empty cell needs to be placed
+	// on top of the same level number
+	same, level := onSameLevel(flat, R, C, p, row, col)
+	if !same {
+		return false, 0
+	}
+	if level > 0 && rules.BottomOnly {
+		return false, 0
+	}
+	// if we're placing at the bottom layer, validity also requires that p is
+	// touching an existing already placed number
+	if level == 0 && !touching(flat, R, C, p, row, col, 0) {
+		return false, 0
+	}
+	// the same goes for higher levels with EveryLevel and something already on that level
+	if level > 0 && rules.EveryLevel && HasLevel(flat, level) && !touching(flat, R, C, p, row, col, level) {
+		return false, 0
+	}
+	return true, level
+}
+
+func InBounds(flat Storage, p *Piece, row, col int) bool {
+	R, C := flat.Size()
+	return inBounds(R, C, p, row, col)
+}
+
+func inBounds(R, C int, p *Piece, row, col int) bool {
+	/*
+		000
+		000 (row,col) = (1,1)
+		000 (R,C) = (3,3)
+
+		11
+		11 (NR,NC) = (2,2)
+
+		000
+		011 return 1+2-1<3 && 1+2-1<3 = true
+		011
+	*/
+	return row >= 0 && col >= 0 && row+p.Rows-1 < R && col+p.Cols-1 < C
+}
+
+// true if p, when placed at (row,col), is touching a tile at level in flat
+// a cell of flat at level or above has a tile at level, the higher ones sit on it
+// false when p doesn't fit at (row,col)
+func Touching(flat Storage, p *Piece, row, col int, level int8) bool {
+	R, C := flat.Size()
+	if !inBounds(R, C, p, row, col) {
+		return false
+	}
+	return touching(flat, R, C, p, row, col, level)
+}
+
+func touching(flat Storage, R, C int, p *Piece, row, col int, level int8) bool {
+	NR, NC := p.Rows, p.Cols
+	// every row of flat is read once, moving down the piece
+	var above, cells, below []int8
+	if row-1 >= 0 && row-1 < R {
+		above = flat.Row(row - 1)
+	}
+	if row >= 0 && row < R {
+		below = flat.Row(row)
+	}
+	for r := row; r < row+NR && r < R; r++ {
+		if r > row {
+			above = cells
+		}
+		cells, below = below, nil
+		if r+1 < R {
+			below = flat.Row(r + 1)
+		}
+		tiles := p.Tiles[(r-row)*NC : (r-row+1)*NC]
+		for c := col; c < col+NC && c < C; c++ {
+			// number cell is not EMPTY
+			if tiles[c-col] == EMPTY {
+				continue
+			}
+			// top, bottom, left and right neighbors
+			if (above != nil && above[c] >= level) || (below != nil && below[c] >= level) ||
+				(c-1 >= 0 && cells[c-1] >= level) || (c+1 < C && cells[c+1] >= level) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// true and the level p goes on if every tile of p at (row,col) lies on the same level
+// false when p doesn't fit at (row,col)
+func OnSameLevel(flat Storage, p *Piece, row, col int) (bool, int8) {
+	R, C := flat.Size()
+	if !inBounds(R, C, p, row, col) {
+		return false, 0
+	}
+	return onSameLevel(flat, R, C, p, row, col)
+}
+
+func onSameLevel(flat Storage, R, C int, p *Piece, row, col int) (bool, int8) {
+	NR, NC := p.Rows, p.Cols
+	unset := true
+	var fval int8 = -1
+	for r := row; r < row+NR && r < R; r++ {
+		cells := flat.Row(r)
+		tiles := p.Tiles[(r-row)*NC : (r-row+1)*NC]
+		for c := col; c < col+NC && c < C; c++ {
+			if tiles[c-col] == EMPTY {
+				continue
+			}
+			if unset {
+				fval = cells[c]
+				unset = false
+			} else if fval != cells[c] { // if p crosses levels...
+				return false, 0
+			}
+		}
+	}
+	return true, fval + 1
+}
+
+// true if flat has a tile at level, every cell at level or above has one
+func HasLevel(flat Storage, level int8) bool {
+	tlR, tlC, brR, brC := flat.Bounds()
+	for r := tlR; r <= brR; r++ {
+		cells := flat.Row(r)
+		for c := tlC; c <= brC; c++ {
+			if cells[c] >= level {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// top left corners worth trying for p: a valid placement touches or covers a tile,
+// so it can't start more than a piece away from the bounding box, and p has to
+// fit on the board
+// start is below and right of end when there are none
+func Anchors(flat Storage, p *Piece) (int, int, int, int) {
+	R, C := flat.Size()
+	tlR, tlC, brR, brC := flat.Bounds()
+	if tlR > brR {
+		return 0, 0, -1, -1
+	}
+	return max(0, tlR-p.Rows), max(0, tlC-p.Cols), min(R-p.Rows, brR+p.Rows), min(C-p.Cols, brC+p.Cols)
+}
@@ -0,0 +1,180 @@
+package core
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+
+	search = the parts of a game the core can't know, handed in by the backend
+			orients		the orientation codes num is played in, one per look
+			piece		num in orientation o
+			score		what num scores on level
+			horizon		what a position is worth where the lookahead stops, nil to skip
+			visit		called for every position the lookahead looks at, nil to skip
+	anchor = the top left corner of a piece, where a move puts it
+
+*/
+
+// a single placement of num in orientation Orient with its top left corner at (Row,Col)
+type Move struct {
+	Num    int
+	Row    int
+	Col    int
+	Level  int8
+	Orient int `json:",omitempty"`
+}
+
+type Search struct {
+	Rules   Placement
+	Orients func(num int) []int
+	Piece   func(num, o int) *Piece
+	Score   func(num int, level int8) int
+	// m has been counted in seen but not placed on flat
+	Horizon func(flat Storage, m Move, seen []int) int
+	// steps is how many steps the lookahead has left
+	Visit func(steps int)
+}
+
+// check if num in orientation o can be placed at (row,col) using flat
+func (s *Search) Valid(flat Storage, num, o, row, col int) (bool, int8) {
+	return Valid(flat, s.Piece(num, o), row, col, s.Rules)
+}
+
+// every valid placement of num on flat, scanning only around the bounding box
+func (s *Search) LegalMoves(flat Storage, num int) []Move {
+	R, C := flat.Size()
+	moves := []Move{}
+	for _, o := range s.Orients(num) {
+		p := s.Piece(num, o)
+		startR, startC, endR, endC := Anchors(flat, p)
+		for r := startR; r <= endR; r++ {
+			for c := startC; c <= endC; c++ {
+				if valid, level := valid(flat, R, C, p, r, c, s.Rules); valid {
+					moves = append(moves, Move{Num: num, Row: r, Col: c, Level: level, Orient: o})
+				}
+			}
+		}
+	}
+	return moves
+}
+
+// the highest scoring placement of num on flat, looking at every anchor
+// the zero Move when there is none
+func (s *Search) Greedy(flat Storage, num int) Move {
+	R, C := flat.Size()
+	maxScore := math.MinInt
+	var best Move
+	for _, o := range s.Orients(num) {
+		p := s.Piece(num, o)
+		for r := 0; r < R; r++ {
+			for c := 0; c < C; c++ {
+				if valid, level := Valid(flat, p, r, c, s.Rules); valid {
+					newScore := s.Score(num, level)
+					if newScore > maxScore {
+						maxScore = newScore
+						best = Move{Num: num, Row: r, Col: c, Level: level, Orient: o}
+					}
+				}
+			}
+		}
+	}
+	return best
+}
+
+// the placement of num with the highest score over the next steps cards, every
+// card left in deck could come next
+// seen counts the cards placed so far, it is changed while searching and put back
+func (s *Search) BestMove(flat Storage, seen, deck []int, num int, steps int) (Move, int, error) {
+	if s.Visit != nil {
+		s.Visit(steps)
+	}
+	R, C := flat.Size()
+	maxScore := math.MinInt
+	hasValid := false
+	var best Move
+	for _, o := range s.Orients(num) {
+		p := s.Piece(num, o)
+		// for every (r,c) around the bounding box check if num can be placed there in a valid way
+		startR, startC, endR, endC := Anchors(flat, p)
+		for r := startR; r <= endR; r++ {
+			for c := startC; c <= endC; c++ {
+				ok, level := valid(flat, R, C, p, r, c, s.Rules)
+				if !ok {
+					continue
+				}
+				hasValid = true
+				move := Move{Num: num, Row: r, Col: c, Level: level, Orient: o}
+				if newScore := s.value(flat, seen, deck, p, move, steps); newScore > maxScore {
+					maxScore = newScore
+					best = move
+				}
+			}
+		}
+	}
+	if !hasValid {
+		return Move{}, 0, fmt.Errorf("no valid moves for num: %v", num)
+	}
+	return best, maxScore, nil
+}
+
+// what playing move is worth over the next steps cards, its score plus the best
+// the lookahead finds after it, or plus the horizon where the lookahead stops
+func (s *Search) value(flat Storage, seen, deck []int, p *Piece, move Move, steps int) int {
+	num := move.Num
+	// score this move
+	newScore := s.Score(num, move.Level)
+	if steps > 1 {
+		// apply move
+		seen[num]++
+		newFlat := flat.Copy()
+		Place(newFlat, p, move.Row, move.Col, move.Level)
+		// recursively find best move
+		maxScore, found := math.MinInt, false
+		for i := range seen {
+			if seen[i] < deck[i] {
+				_, futureScore, err := s.BestMove(newFlat, seen, deck, i, steps-1)
+				if err == nil && newScore+futureScore > maxScore {
+					maxScore, found = newScore+futureScore, true
+				}
+			}
+		}
+		// undo move to backtrack
+		seen[num]--
+		if found {
+			return maxScore
+		}
+		// no card left fits after move, the lookahead stops here
+	}
+	if s.Horizon != nil {
+		seen[num]++
+		newScore += s.Horizon(flat, move, seen)
+		seen[num]--
+	}
+	return newScore
+}
+
+// a legal move and what the lookahead thinks of it
+type MoveValue struct {
+	Move
+	Value int
+}
+
+// every valid placement of num in orientation o on flat with its value over the next
+// steps cards, the same anchors and values BestMove picks from
+func (s *Search) MoveValues(flat Storage, seen, deck []int, num, o, steps int) []MoveValue {
+	R, C := flat.Size()
+	p := s.Piece(num, o)
+	values := []MoveValue{}
+	startR, startC, endR, endC := Anchors(flat, p)
+	for r := startR; r <= endR; r++ {
+		for c := startC; c <= endC; c++ {
+			if ok, level := valid(flat, R, C, p, r, c, s.Rules); ok {
+				move := Move{Num: num, Row: r, Col: c, Level: level, Orient: o}
+				values = append(values, MoveValue{Move: move, Value: s.value(flat, seen, deck, p, move, steps)})
+			}
+		}
+	}
+	return values
+}
@@ -0,0 +1,71 @@
+package core
+
+import "fmt"
+
+/*
+
+	core = the placement rules and the searches, written once against Storage so every
+		   backend that can store a layer gets all of them
+	storage = how a backend keeps the cells of one layer or flat
+			lib		[][]int8, one slice per row, bounding box worked out when asked
+			lib2	one flat []int8, row after row, bounding box kept up to date
+	layer = every cell holds the number placed there, EMPTY where there is none
+	flat = every cell holds the level of the top tile there, EMPTY where there is none
+	piece = a shape in one orientation, what gets placed
+
+*/
+
+var EMPTY int8 = -1
+
+// one layer or flat of a board, however the backend keeps it
+type Storage interface {
+	Size() (int, int)
+	Get(r, c int) int8
+	// sets a cell, setting a tile grows the bounding box
+	Set(r, c int, v int8)
+	// the cells of row r to read from, the searches read a row at a time
+	Row(r int) []int8
+	// top left and bottom right corners around every tile, top left is
+	// below and right of bottom right when there are none
+	Bounds() (int, int, int, int)
+	// merges layer onto this flat, every tile of layer becomes level
+	Flatten(layer Storage, level int8)
+	Copy() Storage
+}
+
+// a shape in one orientation, Rows*Cols tiles with EMPTY where there is no tile
+type Piece struct {
+	Rows  int
+	Cols  int
+	Tiles []int8
+}
+
+// writes the tiles of p into layer with the top left corner at (row,col), error if
+// p would stick out of layer
+func Put(layer Storage, p *Piece, row, col int) error {
+	if !InBounds(layer, p, row, col) {
+		R, C := layer.Size()
+		return fmt.Errorf("piece %vx%v at (%v,%v) doesn't fit on the %vx%v board", p.Rows, p.Cols, row, col, R, C)
+	}
+	for i := 0; i < p.Rows; i++ {
+		for j := 0; j < p.Cols; j++ {
+			if t := p.Tiles[i*p.Cols+j]; t != EMPTY {
+				layer.Set(row+i, col+j, t)
+			}
+		}
+	}
+	return nil
+}
+
+// writes level into flat everywhere p covers, for searches that only need the
+// shape of the stack
+// p has to fit, the searches only place moves they found valid
+func Place(flat Storage, p *Piece, row, col int, level int8) {
+	for i := 0; i < p.Rows; i++ {
+		for j := 0; j < p.Cols; j++ {
+			if p.Tiles[i*p.Cols+j] != EMPTY {
+				flat.Set(row+i, col+j, level)
+			}
+		}
+	}
+}
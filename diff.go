@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"nmbr9/core"
+	"nmbr9/lib"
+	"nmbr9/lib2"
+	"os"
+)
+
+// nmbr9 diff -games 10 -seed 1 -depths 1,2,3
+func runDiff(args []string) {
+	cfg := core.DefaultDiffConfig()
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	fs.IntVar(&cfg.Games, "games", cfg.Games, "number of random games to play on every backend")
+	fs.Int64Var(&cfg.Seed, "seed", cfg.Seed, "seed of the first game, game i uses seed+i")
+	fs.IntVar(&cfg.R, "rows", cfg.R, "rows of the board")
+	fs.IntVar(&cfg.C, "cols", cfg.C, "columns of the board")
+	fs.IntVar(&cfg.Copies, "copies", cfg.Copies, "copies of each number in the deck")
+	depthList := fs.String("depths", "1,2", "comma separated lookahead steps compared after every card")
+	fs.Parse(args)
+	depths, err := parseCards(*depthList)
+	if err != nil {
+		fmt.Printf("error reading depths: %v\n", err)
+		return
+	}
+	cfg.Depths = depths
+	backends := map[string]core.NewBackend{"lib": lib.NewBackend, "lib2": lib2.NewBackend}
+	m, err := core.Diff(cfg, backends)
+	if err != nil {
+		fmt.Printf("error diffing: %v\n", err)
+		return
+	}
+	if m != nil {
+		fmt.Print(m.String())
+		os.Exit(1)
+	}
+	fmt.Printf("lib and lib2 agree on %v games\n", cfg.Games)
+}
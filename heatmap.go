@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"nmbr9/lib2"
+	"os"
+)
+
+// nmbr9 heatmap -game game.json -num 8 -steps 2 -svg heatmap.svg
+// nmbr9 heatmap -cards 5,9,0 -num 8
+func runHeatmap(args []string) {
+	fs := flag.NewFlagSet("heatmap", flag.ExitOnError)
+	path := fs.String("game", "", "saved game to show the heatmap for, see -save when playing")
+	cardList := fs.String("cards", "", "comma separated cards placed by the lookahead first, instead of -game")
+	num := fs.Int("num", 0, "number to place")
+	orient := fs.Int("o", 0, "orientation code of the number, 0 as drawn, see -orient for which are allowed")
+	steps := fs.Int("steps", 1, "cards searched for every anchor, counting the one placed")
+	svg := fs.String("svg", "", "also write the heatmap as an svg picture to this file")
+	rules := addRuleFlags(fs)
+	fs.Parse(args)
+	board, err := heatmapBoard(*path, *cardList, rules, *steps)
+	if err != nil {
+		fmt.Printf("error setting up the board: %v\n", err)
+		return
+	}
+	h, err := board.Heatmap(*num, *orient, *steps)
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		return
+	}
+	board.PrintIso()
+	fmt.Print(h.Render())
+	if *svg != "" {
+		if err := os.WriteFile(*svg, []byte(h.SVG()), 0644); err != nil {
+			fmt.Printf("error writing svg: %v\n", err)
+		}
+	}
+}
+
+// the board from the saved game or with the cards played on it
+func heatmapBoard(path, cardList string, rules *ruleFlags, steps int) (*lib2.Board, error) {
+	if path != "" {
+		rec, err := lib2.LoadGame(path)
+		if err != nil {
+			return nil, err
+		}
+		return rec.Replay()
+	}
+	r, err := rules.Rules()
+	if err != nil {
+		return nil, err
+	}
+	board, err := lib2.NewBoardWithRules(r)
+	if err != nil {
+		return nil, err
+	}
+	if cardList == "" {
+		return board, nil
+	}
+	cards, err := parseCards(cardList)
+	if err != nil {
+		return nil, err
+	}
+	for _, num := range cards {
+		if err, _ := board.ApplyBestMove(num, steps); err != nil {
+			return nil, fmt.Errorf("card %v: %v", num, err)
+		}
+	}
+	return board, nil
+}
@@ -0,0 +1,55 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+
+	"nmbr9/core"
+)
+
+func FuzzApplyBestMove(f *testing.F) {
+	f.Add(uint8(12), uint8(12), uint8(2), []byte{9, 8, 7, 6, 5, 4})
+	f.Fuzz(func(t *testing.T, rows, cols, copies uint8, cards []byte) {
+		board := newBoardRC(int(rows%16), int(cols%16), int(copies%4))
+		for _, card := range cards[:min(len(cards), 6)] {
+			// -1 and 10 are not numbers, the top bit looks 2 steps ahead
+			num := int(card%128%12) - 1
+			placed := len(board.layers)
+			if err, _ := board.ApplyBestMove(num, 1+int(card>>7)); err == nil && len(board.layers) < placed {
+				t.Fatalf("num:%v lost a level", num)
+			}
+		}
+	})
+}
+
+// every move ApplyMove takes after the first has to be one LegalMoves offered
+func FuzzApplyMove(f *testing.F) {
+	f.Add(uint8(12), uint8(12), uint8(2), []byte{9, 0, 0, 8, 4, 7, 8, 4, 4})
+	f.Fuzz(func(t *testing.T, rows, cols, copies uint8, moves []byte) {
+		board := newBoardRC(int(rows%16), int(cols%16), int(copies%4))
+		for i := 0; i+2 < len(moves) && i < 3*12; i += 3 {
+			m := core.Move{Num: int(int8(moves[i])), Row: int(int8(moves[i+1])), Col: int(int8(moves[i+2]))}
+			first := len(board.layers) == 0
+			legal := board.LegalMoves(m.Num)
+			if err := board.ApplyMove(m); err != nil || first {
+				continue
+			}
+			found := false
+			for _, l := range legal {
+				found = found || (l.Row == m.Row && l.Col == m.Col)
+			}
+			if !found {
+				t.Fatalf("%+v placed but not a legal move", m)
+			}
+		}
+	})
+}
+
+func FuzzColor(f *testing.F) {
+	f.Add(int8(9))
+	f.Fuzz(func(t *testing.T, num int8) {
+		if !strings.Contains(color(num, "%v"), "%v") {
+			t.Fatalf("num:%v format missing from its colour", num)
+		}
+	})
+}
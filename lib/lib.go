@@ -3,6 +3,8 @@ package lib
 import (
 	"fmt"
 	"strings"
+
+	"nmbr9/core"
 )
 
 /*
@@ -123,43 +125,119 @@ func (board *Board) addLayer() {
 	board.layers = append(board.layers, makeLayerRC(board.R, board.C))
 }
 
-func (board *Board) putNumberAtLayer(level int8, num, row, col int) {
-	if int(level) >= len(board.layers) {
+// error if num doesn't fit at (row,col) or level would leave a level empty under it,
+// nothing is placed then
+func (board *Board) putNumberAtLayer(level int8, num, row, col int) error {
+	if level < 0 || int(level) > len(board.layers) {
+		return fmt.Errorf("num:%v can't go on level:%v of a board with %v levels", num, level, len(board.layers))
+	}
+	if !isInBounds(board.flat, num, row, col) {
+		return fmt.Errorf("num:%v can't be placed at (%v,%v)", num, row, col)
+	}
+	if int(level) == len(board.layers) {
 		board.addLayer()
 	}
 	layer := board.layers[level]
-	putNumber(layer, num, row, col)
-	board.flat = flatten(board.flat, layer, level)
+	if err := putNumber(layer, num, row, col); err != nil {
+		return err
+	}
+	// only the new piece, flattening all of layer would bury tiles stacked on it
+	core.Place(grid(board.flat), pieces[num], row, col, level)
 	board.seen[num]++
+	return nil
 }
 
-func (board *Board) setBaseLayer(num int) {
+func (board *Board) setBaseLayer(num int) error {
 	// put base layer number in the middle since board should be empty
 	NR, NC := getNumberSize(NUMBER[num])
+	if NR > board.R || NC > board.C {
+		return fmt.Errorf("num:%v is %vx%v and doesn't fit on a %vx%v board", num, NR, NC, board.R, board.C)
+	}
 	midR := (board.R - NR) / 2
 	midC := (board.C - NC) / 2
-	board.putNumberAtLayer(0, num, midR, midC)
+	return board.putNumberAtLayer(0, num, midR, midC)
 }
 
 // steps: how many steps to look ahead
 // limit: how many steps until game ends
 func (board *Board) ApplyBestMove(num int, steps int) (error, int) {
+	if err := board.checkNum(num); err != nil {
+		return err, 0
+	}
 	if len(board.layers) == 0 {
-		board.setBaseLayer(num)
-		return nil, 0
+		return board.setBaseLayer(num), 0
 	} else {
-		if board.seen[num] >= board.seenLimit {
-			return fmt.Errorf("num:%v has already been seen limit:%v times", num, board.seenLimit), 0
-		}
 		bestR, bestC, bestLevel, maxScore, err := findBestMoveV2(board.flat, board.seen, board.seenLimit, num, steps)
 		if err != nil {
 			return err, 0
 		}
-		board.putNumberAtLayer(bestLevel, num, bestR, bestC)
+		if err := board.putNumberAtLayer(bestLevel, num, bestR, bestC); err != nil {
+			return err, 0
+		}
 		return nil, maxScore
 	}
 }
 
+// places m.Num at (m.Row,m.Col) if that is a valid move, the level is worked out from
+// the flat, the first number goes in the middle
+func (board *Board) ApplyMove(m core.Move) error {
+	if err := board.checkNum(m.Num); err != nil {
+		return err
+	}
+	if m.Orient != 0 {
+		return fmt.Errorf("num:%v can't be turned, orientation:%v", m.Num, m.Orient)
+	}
+	if len(board.layers) == 0 {
+		return board.setBaseLayer(m.Num)
+	}
+	valid, level := isValid(board.flat, m.Num, m.Row, m.Col)
+	if !valid {
+		return fmt.Errorf("num:%v can't be placed at (%v,%v)", m.Num, m.Row, m.Col)
+	}
+	return board.putNumberAtLayer(level, m.Num, m.Row, m.Col)
+}
+
+// every valid placement of num, the first number always goes in the middle
+// none if num is not a number
+func (board *Board) LegalMoves(num int) []core.Move {
+	if num < 0 || num >= len(NUMBER) {
+		return []core.Move{}
+	}
+	if len(board.layers) == 0 {
+		NR, NC := getNumberSize(NUMBER[num])
+		return []core.Move{{Num: num, Row: (board.R - NR) / 2, Col: (board.C - NC) / 2}}
+	}
+	return search.LegalMoves(grid(board.flat), num)
+}
+
+// the move ApplyBestMove would make for num and its score, without making it
+func (board *Board) BestMove(num int, steps int) (core.Move, int, error) {
+	if err := board.checkNum(num); err != nil {
+		return core.Move{}, 0, err
+	}
+	if len(board.layers) == 0 {
+		return board.LegalMoves(num)[0], 0, nil
+	}
+	seen := make([]int, len(board.seen))
+	copy(seen, board.seen)
+	r, c, level, maxScore, err := findBestMoveV2(board.flat, seen, board.seenLimit, num, steps)
+	if err != nil {
+		return core.Move{}, 0, err
+	}
+	return core.Move{Num: num, Row: r, Col: c, Level: level}, maxScore, nil
+}
+
+// error if num is not a number or has been placed seenLimit times
+func (board *Board) checkNum(num int) error {
+	if num < 0 || num >= len(NUMBER) {
+		return fmt.Errorf("num:%v is not a number", num)
+	}
+	if board.seen[num] >= board.seenLimit {
+		return fmt.Errorf("num:%v has already been seen limit:%v times", num, board.seenLimit)
+	}
+	return nil
+}
+
 func (board *Board) PrintOverlays() {
 	overlays := make([][][]int8, len(board.layers))
 	lay := makeLayerRC(board.R, board.C)
@@ -175,74 +253,20 @@ func (board *Board) printFlat() {
 }
 
 func findBestMoveV2(flat [][]int8, seen []int, seenLimit int, num int, steps int) (int, int, int8, int, error) {
-	maxScore := -10000
-	R, C := getLayerSize(flat)
-	hasValid := false
-	var bestR, bestC int
-	var bestLevel int8
-	// for every (r,c) check if num can be placed there in a valid way
-	for r := 0; r < R; r++ {
-		for c := 0; c < C; c++ {
-			if valid, level := isValid(flat, num, r, c); valid {
-				hasValid = true
-				// score this move
-				newScore := score(num, level)
-				if newScore > maxScore {
-					maxScore = newScore
-					bestR, bestC = r, c
-					bestLevel = level
-				}
-				if steps > 1 {
-					// apply move
-					layer := makeLayerRC(R, C)
-					putNumber(layer, num, r, c)
-					seen[num]++
-					// compute new flat
-					newFlat := copyFlat(flat)
-					flatten(newFlat, layer, level)
-					// recursively find best move
-					for s := 0; s < seenLimit; s++ {
-						for i := range seen {
-							if seen[i] < seenLimit {
-								_, _, _, futureScore, err := findBestMoveV2(newFlat, seen, seenLimit, i, steps-1)
-								if err == nil && newScore+futureScore > maxScore {
-									maxScore = newScore + futureScore
-									bestR, bestC = r, c
-									bestLevel = level
-								}
-							}
-						}
-					}
-					// undo move to backtrack
-					seen[num]--
-				}
-			}
-		}
+	deck := make([]int, len(seen))
+	for i := range deck {
+		deck[i] = seenLimit
 	}
-	if !hasValid {
-		return 0, 0, 0, 0, fmt.Errorf("no valid moves for num: %v", num)
+	m, maxScore, err := search.BestMove(grid(flat), seen, deck, num, steps)
+	if err != nil {
+		return 0, 0, 0, 0, err
 	}
-	return bestR, bestC, bestLevel, maxScore, nil
+	return m.Row, m.Col, m.Level, maxScore, nil
 }
 
 func findBestMove(flat [][]int8, num int) (int, int, int8) {
-	R, C := getLayerSize(flat)
-	maxScore := -1
-	var bestR, bestC int
-	var bestLevel int8
-	for r := 0; r < R; r++ {
-		for c := 0; c < C; c++ {
-			if valid, level := isValid(flat, num, r, c); valid {
-				newScore := score(num, level)
-				if newScore > maxScore {
-					maxScore = newScore
-					bestR, bestC = r, c
-					bestLevel = level
-				}
-			}
-		}
-	}
-	return bestR, bestC, bestLevel
+	m := search.Greedy(grid(flat), num)
+	return m.Row, m.Col, m.Level
 }
 
 func copyLayer(layer [][]int8) [][]int8 {
@@ -264,113 +288,17 @@ func score(num int, level int8) int {
 
 // check if num can be placed at (row,col) using flat
 func isValid(flat [][]int8, num int, row, col int) (bool, int8) {
-	if !isInBounds(flat, num, row, col) {
-		return false, 0
-	}
-	// validity requires that every non-empty cell needs to be placed
-	// on top of the same level number
-	same, level := isOnSameLevel(flat, num, row, col)
-	if !same {
-		return false, 0
-	}
-	// if we're placing at the bottom layer, validity also requires that num is
-	// touching an existing already placed number
-	if level == 0 && !isTouching(flat, num, row, col) {
-		return false, 0
-	}
-	return true, level
+	return core.Valid(grid(flat), pieces[num], row, col, core.Placement{})
 }
 
 func isInBounds(flat [][]int8, num int, row, col int) bool {
-	n := NUMBER[num]
-	NR, NC := getNumberSize(n)
-	R, C := getLayerSize(flat)
-	/*
-		000
-		000 (row,col) = (1,1)
-		000 (R,C) = (3,3)
-
-		11
-		11 (NR,NC) = (2,2)
-
-		000
-		011 return 1+2-1<3 && 1+2-1<3 = true
-		011
-	*/
-	return row+NR-1 < R && col+NC-1 < C
-}
-
-func isTouching(flat [][]int8, num int, row, col int) bool {
-	n := NUMBER[num]
-	NR, NC := getNumberSize(n)
-	touching := false
-outer:
-	for r := row; r < row+NR && r < len(flat); r++ {
-		for c := col; c < col+NC && c < len(flat[r]); c++ {
-			// number cell is not EMPTY
-			if n[r-row][c-col] != EMPTY {
-				// top neighbor
-				if r-1 >= 0 && flat[r-1][c] >= 0 {
-					touching = true
-					break outer
-				}
-				// bottom neighbor
-				if r+1 < len(flat) && flat[r+1][c] >= 0 {
-					touching = true
-					break outer
-				}
-				// left neighbor
-				if c-1 >= 0 && flat[r][c-1] >= 0 {
-					touching = true
-					break outer
-				}
-				// right neighbor
-				if c+1 < len(flat[r]) && flat[r][c+1] >= 0 {
-					touching = true
-					break outer
-				}
-			}
-		}
-	}
-	return touching
-}
-
-func isOnSameLevel(flat [][]int8, num int, row, col int) (bool, int8) {
-	n := NUMBER[num]
-	NR, NC := getNumberSize(n)
-	unset := true
-	var fval int8 = -1
-	// validity requires that every non-empty cell needs to be placed
-	// on top of the same level number
-	for r := row; r < row+NR && r < len(flat); r++ {
-		for c := col; c < col+NC && c < len(flat[r]); c++ {
-			if n[r-row][c-col] >= 0 { // if num has non-empty cell...
-				if unset {
-					fval = flat[r][c]
-					unset = false
-				} else {
-					if fval != flat[r][c] { // if num crosses levels...
-						return false, 0
-					}
-				}
-			}
-		}
-	}
-	level := fval + 1
-	return true, level
+	return core.InBounds(grid(flat), pieces[num], row, col)
 }
 
 // merges layer onto flat, modifying flat
 // level is the level of layer
 func flatten(flat, layer [][]int8, level int8) [][]int8 {
-	R, C := getLayerSize(flat)
-	for r := 0; r < R; r++ {
-		for c := 0; c < C; c++ {
-			if layer[r][c] != EMPTY {
-				flat[r][c] = level
-			}
-		}
-	}
+	grid(flat).Flatten(grid(layer), level)
 	return flat
 }
 
@@ -386,16 +314,8 @@ func overlay(layer1, layer2 [][]int8) {
 	}
 }
 
-func putNumber(layer [][]int8, num, row, col int) {
-	n := NUMBER[num]
-	NR, NC := getNumberSize(n)
-	for i := row; i < row+NR; i++ {
-		for j := col; j < col+NC; j++ {
-			if n[i-row][j-col] != EMPTY {
-				layer[i][j] = n[i-row][j-col]
-			}
-		}
-	}
+func putNumber(layer [][]int8, num, row, col int) error {
+	return core.Put(grid(layer), pieces[num], row, col)
 }
 
 func makeFlatRC(rows, cols int) [][]int8 {
@@ -485,5 +405,8 @@ var COLOR = []string{
 }
 
 func color(num int8, str string) string {
+	if num < 0 || int(num) >= len(COLOR) {
+		return str
+	}
 	return COLOR[num] + str + Reset
 }
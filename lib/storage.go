@@ -0,0 +1,102 @@
+package lib
+
+import "nmbr9/core"
+
+/*
+
+	grid is the lib storage for core, one slice per row like every layer in lib
+	nothing keeps a bounding box, it is worked out from the tiles when asked
+
+*/
+
+type grid [][]int8
+
+// an empty layer or flat for core, every cell EMPTY
+func NewStorage(rows, cols int) core.Storage {
+	return grid(makeLayerRC(rows, cols))
+}
+
+func (g grid) Size() (int, int) {
+	return getLayerSize(g)
+}
+
+func (g grid) Get(r, c int) int8 {
+	return g[r][c]
+}
+
+func (g grid) Set(r, c int, v int8) {
+	g[r][c] = v
+}
+
+func (g grid) Row(r int) []int8 {
+	return g[r]
+}
+
+func (g grid) Bounds() (int, int, int, int) {
+	tlR, tlC, brR, brC := len(g), 0, -1, -1
+	if len(g) > 0 {
+		tlC = len(g[0])
+	}
+	for r := range g {
+		for c, v := range g[r] {
+			if v != EMPTY {
+				tlR, tlC = min(tlR, r), min(tlC, c)
+				brR, brC = max(brR, r), max(brC, c)
+			}
+		}
+	}
+	if brR < 0 {
+		return 0, 0, -1, -1
+	}
+	return tlR, tlC, brR, brC
+}
+
+func (g grid) Flatten(layer core.Storage, level int8) {
+	R, C := g.Size()
+	for r := 0; r < R; r++ {
+		cells := layer.Row(r)
+		for c := 0; c < C; c++ {
+			if cells[c] != EMPTY {
+				g[r][c] = level
+			}
+		}
+	}
+}
+
+func (g grid) Copy() core.Storage {
+	return grid(copyFlat(g))
+}
+
+// an empty board for the core diff harness
+func NewBackend(rows, cols, copies int) core.Backend {
+	return newBoardRC(rows, cols, copies)
+}
+
+// a copy of the flat, every cell the level of its top tile
+func (board *Board) Flat() core.Storage {
+	return grid(copyFlat(board.flat))
+}
+
+// every number as a core piece, lib plays them as drawn
+var pieces = makePieces()
+
+func makePieces() []*core.Piece {
+	pieces := make([]*core.Piece, len(NUMBER))
+	for num, n := range NUMBER {
+		NR, NC := getNumberSize(n)
+		p := &core.Piece{Rows: NR, Cols: NC}
+		for _, row := range n {
+			p.Tiles = append(p.Tiles, row...)
+		}
+		pieces[num] = p
+	}
+	return pieces
+}
+
+var asDrawn = []int{0}
+
+var search = &core.Search{
+	Orients: func(num int) []int { return asDrawn },
+	Piece:   func(num, o int) *core.Piece { return pieces[num] },
+	Score:   score,
+}
@@ -0,0 +1,76 @@
+package lib2
+
+import "fmt"
+
+/*
+
+	adjacency = which placements have to touch a tile already on their own level
+			bottom			only level 0, the standard rule, higher pieces just need
+							to lie flat on the pieces below them
+			every-level		level 0 and, once a level has a tile, every later piece on
+							that level too
+				flat:		ok:			no:			x = new piece on level 1
+				.0000.		.0000.		.0000.		level 1 has tiles, so x
+				.1100.		.11xx.		.1100.		has to touch one of them
+				.1.00.		.1.xx.		.1.00.
+				.1.00.		.1.xx.		.1.xx.
+				.0000.		.0000.		.00xx.
+
+*/
+
+type Adjacency int
+
+const (
+	TouchBottom Adjacency = iota
+	TouchEveryLevel
+)
+
+const AdjacencyNames = "bottom, every-level"
+
+var adjacencyNames = []string{"bottom", "every-level"}
+
+func AdjacencyByName(name string) (Adjacency, error) {
+	for a, n := range adjacencyNames {
+		if n == name {
+			return Adjacency(a), nil
+		}
+	}
+	return TouchBottom, fmt.Errorf("unknown adjacency: %v", name)
+}
+
+func (a Adjacency) String() string {
+	if a < TouchBottom || a > TouchEveryLevel {
+		return fmt.Sprintf("adjacency(%d)", int(a))
+	}
+	return adjacencyNames[a]
+}
+
+// saved by name
+func (a Adjacency) MarshalText() ([]byte, error) {
+	if a < TouchBottom || a > TouchEveryLevel {
+		return nil, fmt.Errorf("unknown adjacency: %d", int(a))
+	}
+	return []byte(a.String()), nil
+}
+
+func (a *Adjacency) UnmarshalText(text []byte) error {
+	var err error
+	*a, err = AdjacencyByName(string(text))
+	return err
+}
+
+func (board *Board) Adjacency() Adjacency {
+	return board.rules.Adjacency
+}
+
+// plays the game with a, only before the first card like the orientations
+func (board *Board) SetAdjacency(a Adjacency) error {
+	if a < TouchBottom || a > TouchEveryLevel {
+		return fmt.Errorf("unknown adjacency: %d", int(a))
+	}
+	if len(board.drawn) > 0 {
+		return fmt.Errorf("adjacency can only change before the first card")
+	}
+	board.rules.Adjacency = a
+	return nil
+}
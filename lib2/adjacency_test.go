@@ -0,0 +1,127 @@
+package lib2
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// a 4x6 slab to build levels on and 2x2 squares to stack on it
+func slabShapes() *ShapeSet {
+	return &ShapeSet{Name: "slabs", Shapes: []*Shape{
+		{Name: "slab", Rows: 4, Cols: 6, Value: 0, Copies: 1, Cells: []string{"######", "######", "######", "######"}},
+		{Name: "square", Rows: 2, Cols: 2, Value: 1, Copies: 6, Cells: []string{"##", "##"}},
+	}}
+}
+
+func TestTouchEveryLevel(t *testing.T) {
+	set := slabShapes()
+	tests := []struct {
+		setup     [][3]int // level, row, col of every square on the slab
+		next      [2]int
+		wantLevel int8
+		wantValid bool // with every-level, bottom always allows these
+	}{
+		/*
+			flat:     next:
+			11....    11..xx
+			11....    11..xx
+			......    ......
+			......    ......
+		*/
+		{setup: [][3]int{{1, 0, 0}}, next: [2]int{0, 4}, wantLevel: 1, wantValid: false},
+		/*
+			flat:     next:
+			11....    11xx..
+			11....    11xx..
+			......    ......
+			......    ......
+		*/
+		{setup: [][3]int{{1, 0, 0}}, next: [2]int{0, 2}, wantLevel: 1, wantValid: true},
+		/*
+			nothing on level 2 yet, anywhere on level 1 will do
+			flat:     next:
+			11....    xx....
+			11....    xx....
+		*/
+		{setup: [][3]int{{1, 0, 0}}, next: [2]int{0, 0}, wantLevel: 2, wantValid: true},
+		/*
+			the 1s under the 2s are still on level 1
+			flat:     next:
+			1221..    1221..
+			1221..    1221..
+			......    .xx...
+			......    .xx...
+		*/
+		{setup: [][3]int{{1, 0, 0}, {1, 0, 2}, {2, 0, 1}}, next: [2]int{2, 1}, wantLevel: 1, wantValid: true},
+		/*
+			flat:     next:
+			1221..    1221..
+			1221..    1221..
+			......    ....xx
+			......    ....xx
+		*/
+		{setup: [][3]int{{1, 0, 0}, {1, 0, 2}, {2, 0, 1}}, next: [2]int{2, 4}, wantLevel: 1, wantValid: false},
+	}
+	for i, tt := range tests {
+		board, err := NewBoardWithShapes(4, 6, set)
+		if err != nil {
+			t.Fatalf("err making board: %v", err)
+		}
+		layer := board.addLayer()
+		board.putNumber(layer, 0, 0, 0, 0)
+		flat := board.flatten(board.flat, layer, 0)
+		for _, sq := range tt.setup {
+			layer := makeLayerRC(board.R, board.C)
+			board.putNumber(layer, 1, 0, sq[1], sq[2])
+			flat = board.flatten(flat, layer, int8(sq[0]))
+		}
+		valid, level := board.isValid(flat, 1, 0, tt.next[0], tt.next[1])
+		if !valid || level != tt.wantLevel {
+			t.Fatalf("test %v bottom: want:true,%v != got:%v,%v", i, tt.wantLevel, valid, level)
+		}
+		board.rules.Adjacency = TouchEveryLevel
+		valid, level = board.isValid(flat, 1, 0, tt.next[0], tt.next[1])
+		if valid != tt.wantValid {
+			t.Fatalf("test %v every-level valid: want:%v != got:%v", i, tt.wantValid, valid)
+		}
+		if valid && level != tt.wantLevel {
+			t.Fatalf("test %v every-level level: want:%v != got:%v", i, tt.wantLevel, level)
+		}
+	}
+}
+
+func TestAdjacencyRecorded(t *testing.T) {
+	board, err := NewBoardWithShapes(4, 6, slabShapes())
+	if err != nil {
+		t.Fatalf("err making board: %v", err)
+	}
+	if err := board.SetAdjacency(TouchEveryLevel); err != nil {
+		t.Fatalf("err setting adjacency: %v", err)
+	}
+	for _, m := range []Move{{Num: 0}, {Num: 1, Row: 0, Col: 0}, {Num: 1, Row: 0, Col: 2}} {
+		if err := board.ApplyMove(m); err != nil {
+			t.Fatalf("err applying %v: %v", m, err)
+		}
+	}
+	if err := board.ApplyMove(Move{Num: 1, Row: 2, Col: 4}); err == nil {
+		t.Fatalf("square away from level 1 should not be allowed")
+	}
+	if err := board.SetAdjacency(TouchBottom); err == nil {
+		t.Fatalf("adjacency should not change mid game")
+	}
+	path := filepath.Join(t.TempDir(), "game.json")
+	if err := board.SaveGame(path); err != nil {
+		t.Fatalf("err saving: %v", err)
+	}
+	rec, err := LoadGame(path)
+	if err != nil {
+		t.Fatalf("err loading: %v", err)
+	}
+	replayed, err := rec.Replay()
+	if err != nil {
+		t.Fatalf("err replaying: %v", err)
+	}
+	if replayed.Adjacency() != TouchEveryLevel {
+		t.Fatalf("adjacency: want:%v != got:%v", TouchEveryLevel, replayed.Adjacency())
+	}
+}
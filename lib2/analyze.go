@@ -0,0 +1,200 @@
+package lib2
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+/*
+
+	analysis = a finished game played back card by card, with the engine searching every
+			   position again and comparing its favourite move to the one that was played
+	value = points a move makes now plus the expected points of the draws after it,
+			searched Steps cards deep, or exactly once the endgame threshold is reached
+	regret = best value minus played value, the expected points the played move gave away
+	mistake = a move with regret, the costliest ones are listed with the better placement
+
+*/
+
+type AnalyzeConfig struct {
+	Steps   int // cards searched for every move, counting the one placed
+	Endgame int // cards left below which values are exact, 0 to skip
+	Top     int // mistakes listed
+}
+
+func DefaultAnalyzeConfig() AnalyzeConfig {
+	return AnalyzeConfig{Steps: 2, Endgame: 3, Top: 3}
+}
+
+type MoveReport struct {
+	Advice
+	Card    int  // 1 based position of the card in the game
+	Num     int  // the card drawn
+	Skipped bool // the card fit nowhere, nothing else is set
+}
+
+type Analysis struct {
+	Config      AnalyzeConfig
+	Moves       []MoveReport
+	Scoring     string // name of the scoring the game was played with
+	Score       int
+	TotalRegret float64
+}
+
+// replays rec and scores every move against the engine's choice
+func Analyze(rec *GameRecord, cfg AnalyzeConfig) (*Analysis, error) {
+	if cfg.Steps < 1 {
+		return nil, fmt.Errorf("steps must be at least 1, got:%v", cfg.Steps)
+	}
+	// the whole record is checked first, replaying card by card below stops at the cards
+	if _, err := rec.Replay(); err != nil {
+		return nil, err
+	}
+	board, err := rec.NewBoard()
+	if err != nil {
+		return nil, err
+	}
+	board.SetEndgame(cfg.Endgame)
+	analysis := &Analysis{Config: cfg, Scoring: board.rules.Scoring.Name}
+	for k, num := range rec.Cards {
+		report := MoveReport{Card: k + 1, Num: num}
+		played, ok := rec.moveFor(board, k)
+		if !ok {
+			report.Skipped = true
+		} else {
+			advice, err := board.Advise(played, cfg.Steps)
+			if err != nil {
+				return nil, fmt.Errorf("card %v: %v", k+1, err)
+			}
+			report.Advice = advice
+			analysis.TotalRegret += advice.Regret
+		}
+		if err := rec.replayCard(board, k); err != nil {
+			return nil, err
+		}
+		analysis.Moves = append(analysis.Moves, report)
+	}
+	analysis.Score = board.Score()
+	return analysis, nil
+}
+
+// every legal move for num with its value, and whether the values are exact
+func (board *Board) moveValues(num int, steps int) ([]Move, []float64, bool) {
+	moves := board.LegalMoves(num)
+	values := make([]float64, len(moves))
+	remaining := board.Remaining()
+	remaining[num]--
+	exact := board.inEndgame(num)
+	var e *endgame
+	if exact {
+		e = &endgame{board: board, memo: map[string]float64{}}
+	}
+	total := 0
+	for _, n := range remaining {
+		total += n
+	}
+	board.fanout = make([]int, steps)
+	for i, m := range moves {
+		values[i] = float64(board.score(num, m.Level))
+		newFlat := copyFlat(board.flat)
+		board.placeOnFlat(newFlat, m)
+		if exact {
+			values[i] += e.value(newFlat, remaining)
+			continue
+		}
+		if steps == 1 || total == 0 {
+			continue
+		}
+		for next, n := range remaining {
+			if n == 0 {
+				continue
+			}
+			remaining[next]--
+			_, future, err := board.expectimax(newFlat, remaining, next, steps-1)
+			remaining[next]++
+			if err != nil {
+				// the card fits nowhere
+				future = float64(board.rules.Scoring.Unused)
+			}
+			values[i] += float64(n) / float64(total) * future
+		}
+	}
+	return moves, values, exact
+}
+
+// the moves with the most regret, costliest first, at most top of them
+func (a *Analysis) Mistakes(top int) []MoveReport {
+	mistakes := []MoveReport{}
+	for _, r := range a.Moves {
+		// values are sums of fractions, ignore rounding noise
+		if !r.Skipped && r.Regret > 1e-9 {
+			mistakes = append(mistakes, r)
+		}
+	}
+	sort.SliceStable(mistakes, func(i, j int) bool {
+		return mistakes[i].Regret > mistakes[j].Regret
+	})
+	if len(mistakes) > top {
+		mistakes = mistakes[:top]
+	}
+	return mistakes
+}
+
+func (a *Analysis) PrintText(w io.Writer) {
+	fmt.Fprintf(w, "%4v %3v  %-16v %-16v %8v %8v %8v\n", "card", "num", "played", "engine", "played", "best", "regret")
+	for _, r := range a.Moves {
+		if r.Skipped {
+			fmt.Fprintf(w, "%4v %3v  fits nowhere\n", r.Card, r.Num)
+			continue
+		}
+		fmt.Fprintf(w, "%4v %3v  %-16v %-16v %8.2f %8.2f %8.2f  %v\n", r.Card, r.Num,
+			placement(r.Played), placement(r.Best), r.PlayedValue, r.BestValue, r.Regret, r.kind())
+	}
+	fmt.Fprintf(w, "score %v (%v)  expected points lost %.2f\n", a.Score, a.Scoring, a.TotalRegret)
+	mistakes := a.Mistakes(a.Config.Top)
+	if len(mistakes) == 0 {
+		fmt.Fprintln(w, "no mistakes found")
+		return
+	}
+	fmt.Fprintln(w, "costliest mistakes:")
+	for _, r := range mistakes {
+		fmt.Fprintf(w, "  card %v (%v): played %v, %v was worth %.2f more\n", r.Card, r.Num, placement(r.Played), placement(r.Best), r.Regret)
+	}
+}
+
+func (a *Analysis) PrintMarkdown(w io.Writer) {
+	fmt.Fprintln(w, "# Game analysis")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Score **%v** with %v scoring, expected points lost **%.2f** (search depth %v, exact below %v cards).\n",
+		a.Score, a.Scoring, a.TotalRegret, a.Config.Steps, a.Config.Endgame)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "| card | num | played | engine | played value | best value | regret | |")
+	fmt.Fprintln(w, "|---:|---:|---|---|---:|---:|---:|---|")
+	for _, r := range a.Moves {
+		if r.Skipped {
+			fmt.Fprintf(w, "| %v | %v | fits nowhere | | | | | |\n", r.Card, r.Num)
+			continue
+		}
+		fmt.Fprintf(w, "| %v | %v | %v | %v | %.2f | %.2f | %.2f | %v |\n", r.Card, r.Num,
+			placement(r.Played), placement(r.Best), r.PlayedValue, r.BestValue, r.Regret, r.kind())
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "## Costliest mistakes")
+	fmt.Fprintln(w)
+	mistakes := a.Mistakes(a.Config.Top)
+	if len(mistakes) == 0 {
+		fmt.Fprintln(w, "None found.")
+		return
+	}
+	for i, r := range mistakes {
+		fmt.Fprintf(w, "%v. Card %v (%v): played %v, **%v** was worth %.2f more.\n", i+1, r.Card, r.Num, placement(r.Played), placement(r.Best), r.Regret)
+	}
+}
+
+func placement(m Move) string {
+	if m.Orient != 0 {
+		return fmt.Sprintf("(%v,%v) level %v orientation %v", m.Row, m.Col, m.Level, m.Orient)
+	}
+	return fmt.Sprintf("(%v,%v) level %v", m.Row, m.Col, m.Level)
+}
@@ -0,0 +1,77 @@
+package lib2
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// a game where every card was placed by the engine itself
+func engineGame(t *testing.T, cards []int) *GameRecord {
+	board := newBoardRC(12, 12, 1)
+	for _, num := range cards {
+		if _, err := board.Play(ExpectimaxStrategy{Steps: 1}, num); err != nil {
+			t.Fatalf("err playing: %v", err)
+		}
+	}
+	return board.Record()
+}
+
+func TestAnalyzeGreedyGameAtDepth1(t *testing.T) {
+	rec := engineGame(t, []int{5, 9, 0, 8, 1, 7, 2})
+	analysis, err := Analyze(rec, AnalyzeConfig{Steps: 1, Top: 3})
+	if err != nil {
+		t.Fatalf("err analyzing: %v", err)
+	}
+	if len(analysis.Moves) != len(rec.Cards) {
+		t.Fatalf("reports: want:%v != got:%v", len(rec.Cards), len(analysis.Moves))
+	}
+	// at depth 1 the analysis searches what the engine searched, so it agrees with every move
+	if analysis.TotalRegret != 0 {
+		t.Fatalf("regret: want:0 != got:%v", analysis.TotalRegret)
+	}
+	if len(analysis.Mistakes(3)) != 0 {
+		t.Fatalf("mistakes: want none != got:%v", analysis.Mistakes(3))
+	}
+}
+
+func TestAnalyzeFindsMistake(t *testing.T) {
+	board := newBoardRC(12, 12, 1)
+	for _, m := range []Move{{Num: 5}, {Num: 9, Row: 0, Col: 3}, {Num: 0, Row: 0, Col: 0}} {
+		if err := board.ApplyMove(m); err != nil {
+			t.Fatalf("err applying %+v: %v", m, err)
+		}
+	}
+	// the 8 fits on top of the 9 and the 0, it is played on level 0 below them instead
+	stacked := Move{Num: 8, Row: 0, Col: 2, Level: 1}
+	flat := Move{Num: 8, Row: 8, Col: 2, Level: 0}
+	legal := board.LegalMoves(8)
+	if !slices.Contains(legal, stacked) || !slices.Contains(legal, flat) {
+		t.Fatalf("%+v and %+v should both be legal: %v", stacked, flat, legal)
+	}
+	if err := board.ApplyMove(flat); err != nil {
+		t.Fatalf("err applying %+v: %v", flat, err)
+	}
+	rec := board.Record()
+	analysis, err := Analyze(rec, AnalyzeConfig{Steps: 2, Endgame: 3, Top: 1})
+	if err != nil {
+		t.Fatalf("err analyzing: %v", err)
+	}
+	mistakes := analysis.Mistakes(1)
+	if len(mistakes) != 1 || mistakes[0].Card != 4 {
+		t.Fatalf("mistakes: want card 4 != got:%+v", mistakes)
+	}
+	if mistakes[0].Regret <= 0 || mistakes[0].Best.Level != 1 {
+		t.Fatalf("mistake: %+v should prefer level 1", mistakes[0])
+	}
+	var text, md bytes.Buffer
+	analysis.PrintText(&text)
+	analysis.PrintMarkdown(&md)
+	if !strings.Contains(text.String(), "costliest mistakes:") {
+		t.Fatalf("text report has no mistakes:\n%v", text.String())
+	}
+	if !strings.Contains(md.String(), "| 4 | 8 |") || !strings.Contains(md.String(), "## Costliest mistakes") {
+		t.Fatalf("markdown report:\n%v", md.String())
+	}
+}
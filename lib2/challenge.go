@@ -0,0 +1,167 @@
+package lib2
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/fs"
+	"math/rand"
+	"os"
+	"sort"
+	"time"
+)
+
+/*
+
+	challenge = one fixed order of the whole 20 card deck, everyone who plays the same
+				seed string gets the same cards, the daily challenge uses the date as seed
+	result = the player's score next to what the engine makes on the same order and the
+			 best line the solver finds, which is the optimum when its proof is complete
+	leaderboard = every result so far, kept in a local file
+
+*/
+
+type Challenge struct {
+	Seed     string
+	Cards    []int
+	Strategy string // the engine scored against, see StrategyByName
+}
+
+// the seed of the daily challenge for the day t falls on
+func DailySeed(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+func NewChallenge(seed string) Challenge {
+	h := fnv.New64a()
+	h.Write([]byte(seed))
+	rng := rand.New(rand.NewSource(int64(h.Sum64())))
+	return Challenge{Seed: seed, Cards: ShuffledDeck(rng, 2), Strategy: "lookahead:2"}
+}
+
+// the engine's rng seed, fixed so its score doesn't depend on what the player's
+// engine was asked before
+const benchmarkSeed = 1
+
+type ChallengeResult struct {
+	Seed     string
+	Player   string
+	Score    int
+	Helped   int    // cards the engine placed or hinted for the player
+	Engine   int    // score of the engine on the same order
+	Strategy string // the engine's strategy
+	Optimum  int    // best line found by the solver, or played by the player or the engine
+	Proven   bool   // Optimum is the optimum, not only the best line found
+	Bound    int    // no line scores more than this
+	Time     time.Time
+}
+
+// the engine's and the solver's scores on the challenge's order, the engine is a new
+// ch.Strategy so every benchmark of a challenge plays the same game
+func (ch Challenge) Benchmark(cfg SolveConfig) (int, *Solution, error) {
+	strategy, err := StrategyByName(ch.Strategy, benchmarkSeed)
+	if err != nil {
+		return 0, nil, err
+	}
+	engine := PlayGame(NewBoard(), ch.Cards, strategy).Score
+	sol, err := NewBoard().Solve(ch.Cards, cfg)
+	return engine, sol, err
+}
+
+// result of a finished challenge board against the engine and the solver
+func (ch Challenge) Result(player string, board *Board, helped int, cfg SolveConfig) (ChallengeResult, error) {
+	engine, sol, err := ch.Benchmark(cfg)
+	if err != nil {
+		return ChallengeResult{}, err
+	}
+	res := ChallengeResult{
+		Seed:     ch.Seed,
+		Player:   player,
+		Score:    board.Score(),
+		Helped:   helped,
+		Engine:   engine,
+		Strategy: ch.Strategy,
+		Optimum:  sol.Value,
+		Proven:   sol.Proof.Complete,
+		Bound:    sol.Proof.RootBound,
+		Time:     time.Now(),
+	}
+	if !res.Proven {
+		// a solver stopped early can be beaten by the lines that were actually played
+		res.Optimum = max(res.Optimum, max(res.Score, res.Engine))
+	} else {
+		res.Bound = res.Optimum
+	}
+	return res, nil
+}
+
+func (res ChallengeResult) Print(w io.Writer) {
+	fmt.Fprintf(w, "%v on %v: %v points", res.Player, res.Seed, res.Score)
+	if res.Helped > 0 {
+		fmt.Fprintf(w, " (engine helped with %v cards)", res.Helped)
+	}
+	fmt.Fprintf(w, "\nengine %v: %v points\n", res.Strategy, res.Engine)
+	if res.Proven {
+		fmt.Fprintf(w, "optimum: %v points\n", res.Optimum)
+	} else {
+		fmt.Fprintf(w, "optimum: between %v and %v points, the solver ran out of nodes\n", res.Optimum, res.Bound)
+	}
+}
+
+type Leaderboard struct {
+	Results []ChallengeResult
+}
+
+// a missing file is an empty leaderboard
+func LoadLeaderboard(path string) (*Leaderboard, error) {
+	lb := &Leaderboard{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return lb, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, lb); err != nil {
+		return nil, fmt.Errorf("error reading leaderboard:%v: %v", path, err)
+	}
+	return lb, nil
+}
+
+func (lb *Leaderboard) Save(path string) error {
+	data, err := json.MarshalIndent(lb, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func (lb *Leaderboard) Add(res ChallengeResult) {
+	lb.Results = append(lb.Results, res)
+}
+
+// results for seed, best score first, unhelped before helped on the same score
+func (lb *Leaderboard) Standings(seed string) []ChallengeResult {
+	results := []ChallengeResult{}
+	for _, res := range lb.Results {
+		if res.Seed == seed {
+			results = append(results, res)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Helped < results[j].Helped
+	})
+	return results
+}
+
+func (lb *Leaderboard) Print(w io.Writer, seed string) {
+	fmt.Fprintf(w, "leaderboard %v\n", seed)
+	for i, res := range lb.Standings(seed) {
+		fmt.Fprintf(w, "%2v. %-12v %4v  helped %v\n", i+1, res.Player, res.Score, res.Helped)
+	}
+}
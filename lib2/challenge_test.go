@@ -0,0 +1,92 @@
+package lib2
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestNewChallenge(t *testing.T) {
+	a := NewChallenge("2026-10-19")
+	b := NewChallenge(DailySeed(time.Date(2026, 10, 19, 23, 0, 0, 0, time.UTC)))
+	if !reflect.DeepEqual(a, b) {
+		t.Fatalf("same seed, different challenges: %v != %v", a.Cards, b.Cards)
+	}
+	if c := NewChallenge("2026-10-20"); reflect.DeepEqual(a.Cards, c.Cards) {
+		t.Fatalf("different seeds, same cards: %v", a.Cards)
+	}
+	counts := make([]int, len(NUMBER))
+	for _, num := range a.Cards {
+		counts[num]++
+	}
+	for num, n := range counts {
+		if n != 2 {
+			t.Fatalf("num:%v is in the deck %v times", num, n)
+		}
+	}
+}
+
+func TestChallengeResult(t *testing.T) {
+	ch := NewChallenge("test")
+	ch.Cards = ch.Cards[:6]
+	board := NewBoard()
+	PlayGame(board, ch.Cards, GreedyStrategy{})
+	ch.Strategy = "lookahead:1"
+	res, err := ch.Result("ann", board, 0, SolveConfig{})
+	if err != nil {
+		t.Fatalf("err scoring: %v", err)
+	}
+	if !res.Proven || res.Optimum < res.Score || res.Optimum < res.Engine || res.Bound != res.Optimum {
+		t.Fatalf("result: %+v", res)
+	}
+}
+
+// the engine's own rng, however much the player's engine was used
+func TestChallengeBenchmarkRepeats(t *testing.T) {
+	ch := NewChallenge("test")
+	ch.Cards = ch.Cards[:6]
+	ch.Strategy = "montecarlo:4"
+	first, _, err := ch.Benchmark(SolveConfig{MaxNodes: 1})
+	if err != nil {
+		t.Fatalf("err benchmarking: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		again, _, err := ch.Benchmark(SolveConfig{MaxNodes: 1})
+		if err != nil {
+			t.Fatalf("err benchmarking: %v", err)
+		}
+		if again != first {
+			t.Fatalf("engine scored %v then %v on the same challenge", first, again)
+		}
+	}
+	if _, _, err := (Challenge{Cards: ch.Cards, Strategy: "nope"}).Benchmark(SolveConfig{}); err == nil {
+		t.Fatalf("unknown strategy should be an error")
+	}
+}
+
+func TestLeaderboard(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "leaderboard.json")
+	lb, err := LoadLeaderboard(path)
+	if err != nil || len(lb.Results) != 0 {
+		t.Fatalf("missing file should be an empty leaderboard, got:%v %v", lb, err)
+	}
+	lb.Add(ChallengeResult{Seed: "a", Player: "ann", Score: 30, Helped: 2})
+	lb.Add(ChallengeResult{Seed: "a", Player: "bob", Score: 30})
+	lb.Add(ChallengeResult{Seed: "b", Player: "cyd", Score: 90})
+	lb.Add(ChallengeResult{Seed: "a", Player: "dan", Score: 40})
+	if err := lb.Save(path); err != nil {
+		t.Fatalf("err saving: %v", err)
+	}
+	lb, err = LoadLeaderboard(path)
+	if err != nil {
+		t.Fatalf("err loading: %v", err)
+	}
+	got := []string{}
+	for _, res := range lb.Standings("a") {
+		got = append(got, res.Player)
+	}
+	if want := []string{"dan", "bob", "ann"}; !reflect.DeepEqual(want, got) {
+		t.Fatalf("standings: want:%v != got:%v", want, got)
+	}
+}
@@ -0,0 +1,76 @@
+package lib2
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+
+	coaching = the player picks every placement, the engine searches the same position
+			   and says how much expected value the player's choice gave away
+	cost = engine's best value minus the value of the player's move, never negative
+		   since the engine tries every legal move including the player's
+
+*/
+
+// the player's move next to the engine's favourite
+type Advice struct {
+	Played      Move
+	Best        Move
+	PlayedValue float64
+	BestValue   float64
+	Regret      float64 // expected points the played move gives away
+	Exact       bool    // values are exact expectations
+	Choices     int     // legal moves there were
+}
+
+// compares placing m.Num at (m.Row,m.Col) in m.Orient with the engine's search of
+// steps cards, the board is not changed, on an empty board the move always goes in the middle
+func (board *Board) Advise(m Move, steps int) (Advice, error) {
+	if err := board.checkNum(m.Num); err != nil {
+		return Advice{}, err
+	}
+	o, err := board.canonical(m.Num, m.Orient)
+	if err != nil {
+		return Advice{}, err
+	}
+	if steps < 1 {
+		return Advice{}, fmt.Errorf("steps must be at least 1, got:%v", steps)
+	}
+	moves, values, exact := board.moveValues(m.Num, steps)
+	advice := Advice{Exact: exact, Choices: len(moves), BestValue: math.Inf(-1)}
+	played := -1
+	for i, move := range moves {
+		if values[i] > advice.BestValue {
+			advice.BestValue = values[i]
+			advice.Best = move
+		}
+		if move.Orient == o && (len(board.layers) == 0 || (move.Row == m.Row && move.Col == m.Col)) {
+			played = i
+		}
+	}
+	if played < 0 {
+		return Advice{}, fmt.Errorf("num:%v can't be placed at (%v,%v)", m.Num, m.Row, m.Col)
+	}
+	advice.Played = moves[played]
+	advice.PlayedValue = values[played]
+	advice.Regret = advice.BestValue - advice.PlayedValue
+	return advice, nil
+}
+
+func (a Advice) kind() string {
+	if a.Exact {
+		return "exact"
+	}
+	return "heuristic"
+}
+
+func (a Advice) String() string {
+	// values are sums of fractions, ignore rounding noise
+	if a.Regret < 1e-9 {
+		return fmt.Sprintf("as good as the engine's choice, value %.2f (%v)", a.PlayedValue, a.kind())
+	}
+	return fmt.Sprintf("that cost you about %.2f expected points, the engine preferred %v",
+		a.Regret, placement(a.Best))
+}
@@ -0,0 +1,42 @@
+package lib2
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAdvise(t *testing.T) {
+	board := NewBoard()
+	// the first card goes in the middle wherever it was asked for
+	advice, err := board.Advise(Move{Num: 5, Row: 0, Col: 0}, 1)
+	if err != nil {
+		t.Fatalf("err advising: %v", err)
+	}
+	if advice.Played != board.LegalMoves(5)[0] || advice.Regret != 0 {
+		t.Fatalf("first move advice: %+v", advice)
+	}
+	board.ApplyMove(advice.Played)
+	if _, err := board.Advise(Move{Num: 9, Row: 0, Col: 0}, 1); err == nil {
+		t.Fatalf("advise should fail on a move that doesn't touch anything")
+	}
+	if _, err := board.Advise(Move{Num: 10}, 1); err == nil {
+		t.Fatalf("advise should fail on a number that doesn't exist")
+	}
+	// every legal move against the best one at depth 1 is just the difference in points
+	moves := board.LegalMoves(9)
+	for _, m := range moves {
+		advice, err := board.Advise(m, 1)
+		if err != nil {
+			t.Fatalf("err advising %+v: %v", m, err)
+		}
+		if advice.Played != m || advice.Choices != len(moves) {
+			t.Fatalf("advice for %+v: %+v", m, advice)
+		}
+		if want := advice.BestValue - float64(board.score(9, m.Level)); advice.Regret != want {
+			t.Fatalf("regret: want:%v != got:%v", want, advice.Regret)
+		}
+		if advice.Regret > 0 && !strings.Contains(advice.String(), "cost you about") {
+			t.Fatalf("advice string: %v", advice)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+//go:build !nmbr9debug
+
+package lib2
+
+const debug = false
@@ -0,0 +1,6 @@
+//go:build nmbr9debug
+
+package lib2
+
+// validate the board after every move
+const debug = true
@@ -0,0 +1,168 @@
+package lib2
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+/*
+
+	deck = how many copies of every number are in the game, the standard deck has 2 of
+		   each of 0-9, an expansion might have three 0s and one 9, a teaching deck might
+		   leave some numbers out completely
+	the deck decides which cards can still be drawn, how likely each draw is in the
+	searches, and when the game is over
+	deck status = what is left of the deck for the player to see
+			remaining	copies of every number still to come
+			next		chance of every number being the next card, remaining/cards left
+			high		cards still to come worth at least High points per level, every
+						card is drawn before the game ends so that is also the number
+						expected
+			turns		one card is drawn every turn, so as many as cards left
+
+*/
+
+// copies of every number, indexed by number
+type Deck []int
+
+// copies of every one of numbers
+func UniformDeck(numbers, copies int) Deck {
+	deck := make(Deck, numbers)
+	for i := range deck {
+		deck[i] = copies
+	}
+	return deck
+}
+
+// comma separated copies of every number in order, e.g. 3,2,2,2,2,2,2,2,2,1
+func ParseDeck(spec string) (Deck, error) {
+	deck := Deck{}
+	for _, part := range strings.Split(spec, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("deck:%v has bad count:%v", spec, part)
+		}
+		if n < 0 {
+			return nil, fmt.Errorf("deck:%v count can't be negative", spec)
+		}
+		deck = append(deck, n)
+	}
+	return deck, nil
+}
+
+// cards in the deck
+func (deck Deck) Total() int {
+	total := 0
+	for _, n := range deck {
+		total += n
+	}
+	return total
+}
+
+// every card of the deck, in number order
+func (deck Deck) Cards() []int {
+	return deckCards(deck)
+}
+
+func (deck Deck) Shuffled(rng *rand.Rand) []int {
+	cards := deck.Cards()
+	rng.Shuffle(len(cards), func(i, j int) {
+		cards[i], cards[j] = cards[j], cards[i]
+	})
+	return cards
+}
+
+func (deck Deck) String() string {
+	parts := make([]string, len(deck))
+	for i, n := range deck {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ",")
+}
+
+// drawing a number whose copies have all been drawn already
+type SeenError struct {
+	Num    int
+	Copies int
+}
+
+func (e *SeenError) Error() string {
+	return fmt.Sprintf("num:%v has already been seen limit:%v times", e.Num, e.Copies)
+}
+
+func (board *Board) Deck() Deck {
+	return append(Deck{}, board.rules.Deck...)
+}
+
+// plays the rest of the game with deck, which needs a count for every number and
+// at least as many copies as have been drawn already
+func (board *Board) SetDeck(deck Deck) error {
+	if len(deck) != len(board.rules.Shapes.Shapes) {
+		return fmt.Errorf("deck has %v numbers, the board has %v", len(deck), len(board.rules.Shapes.Shapes))
+	}
+	for num, n := range deck {
+		if board.seen[num] > n {
+			return fmt.Errorf("num:%v has been seen %v times already, more than the %v in the deck", num, board.seen[num], n)
+		}
+	}
+	board.rules.Deck = append(Deck{}, deck...)
+	return nil
+}
+
+// true once every card of the deck has been placed
+func (board *Board) GameOver() bool {
+	for _, n := range board.Remaining() {
+		if n > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+type DeckStatus struct {
+	Remaining []int     // copies of every number still to come
+	Next      []float64 // chance every number is drawn next, all 0 once the deck is empty
+	Left      int       // cards still to come
+	Turns     int       // turns left, one card each
+	High      int       // lowest value counted as high
+	HighLeft  int       // high cards still to come
+}
+
+// what is left of the deck, numbers worth high or more per level count as high
+func (board *Board) DeckStatus(high int) *DeckStatus {
+	status := &DeckStatus{Remaining: board.Remaining(), Next: make([]float64, len(board.seen)), High: high}
+	for num, n := range status.Remaining {
+		status.Left += n
+		if board.shape(num).Value >= high {
+			status.HighLeft += n
+		}
+	}
+	status.Turns = status.Left
+	for num, n := range status.Remaining {
+		if status.Left > 0 {
+			status.Next[num] = float64(n) / float64(status.Left)
+		}
+	}
+	return status
+}
+
+// the panel the game shows after every card, one column per number
+func (status *DeckStatus) Print(w io.Writer) {
+	fmt.Fprintf(w, "deck  %v cards left, %v turns, %v high (%v+) to come\n", status.Left, status.Turns, status.HighLeft, status.High)
+	fmt.Fprint(w, "num  ")
+	for num := range status.Remaining {
+		fmt.Fprintf(w, "%5v", glyph(int8(num)))
+	}
+	fmt.Fprint(w, "\nleft ")
+	for _, n := range status.Remaining {
+		fmt.Fprintf(w, "%5v", n)
+	}
+	fmt.Fprint(w, "\nnext ")
+	for _, p := range status.Next {
+		fmt.Fprintf(w, "%4.0f%%", 100*p)
+	}
+	fmt.Fprintln(w)
+}
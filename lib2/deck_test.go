@@ -0,0 +1,137 @@
+package lib2
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDeck(t *testing.T) {
+	deck, err := ParseDeck("3, 2,2,2,2,2,2,2,2,1")
+	if err != nil {
+		t.Fatalf("err parsing deck: %v", err)
+	}
+	if deck.Total() != 20 || deck[0] != 3 || deck[9] != 1 || deck.String() != "3,2,2,2,2,2,2,2,2,1" {
+		t.Fatalf("deck: %v total %v", deck, deck.Total())
+	}
+	cards := deck.Shuffled(rand.New(rand.NewSource(1)))
+	counts := make(Deck, len(deck))
+	for _, num := range cards {
+		counts[num]++
+	}
+	if !reflect.DeepEqual(counts, deck) {
+		t.Fatalf("shuffled cards: want:%v != got:%v", deck, counts)
+	}
+	for _, spec := range []string{"", "1,x", "1,-1"} {
+		if _, err := ParseDeck(spec); err == nil {
+			t.Fatalf("%q: want error", spec)
+		}
+	}
+}
+
+func TestBoardFollowsDeck(t *testing.T) {
+	// three 0s, one 9 and no 5 at all
+	deck := Deck{3, 1, 1, 1, 1, 0, 1, 1, 1, 1}
+	board := NewBoard()
+	if err := board.SetDeck(deck[:9]); err == nil {
+		t.Fatalf("a deck without a count for 9 should be refused")
+	}
+	if err := board.SetDeck(deck); err != nil {
+		t.Fatalf("err setting deck: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := board.Play(GreedyStrategy{}, 0); err != nil {
+			t.Fatalf("err playing 0 #%v: %v", i+1, err)
+		}
+	}
+	var seenErr *SeenError
+	if _, err := board.Play(GreedyStrategy{}, 0); !errors.As(err, &seenErr) || seenErr.Copies != 3 {
+		t.Fatalf("a fourth 0: want SeenError with 3 copies != got:%v", err)
+	}
+	if err, _ := board.ApplyBestMove(5, 1); !errors.As(err, &seenErr) || seenErr.Num != 5 || seenErr.Copies != 0 {
+		t.Fatalf("a 5: want SeenError with 0 copies != got:%v", err)
+	}
+	if want := (Deck{0, 1, 1, 1, 1, 0, 1, 1, 1, 1}); !reflect.DeepEqual(Deck(board.Remaining()), want) {
+		t.Fatalf("remaining: want:%v != got:%v", want, board.Remaining())
+	}
+	if err := board.SetDeck(UniformDeck(10, 2)); err == nil {
+		t.Fatalf("a deck with fewer 0s than were played should be refused")
+	}
+	// the lookahead never draws a number the deck doesn't have
+	seen := append([]int{}, board.seen...)
+	board.fanout = make([]int, 2)
+	board.findBestMoveV2(board.flat, seen, board.rules.Deck, 9, 2)
+	legal := 0
+	for num, n := range board.Remaining() {
+		if n > 0 && num != 9 {
+			legal++
+		}
+	}
+	if board.fanout[1] > legal*len(board.LegalMoves(9)) {
+		t.Fatalf("lookahead searched %v draws, the deck only has %v numbers left", board.fanout[1], legal)
+	}
+	for _, num := range []int{1, 2, 3, 4, 6, 7, 8, 9} {
+		if board.GameOver() {
+			t.Fatalf("game over with num:%v still to come", num)
+		}
+		board.Play(GreedyStrategy{}, num)
+	}
+	if !board.GameOver() {
+		t.Fatalf("game should be over once the whole deck is drawn, remaining: %v", board.Remaining())
+	}
+	replayed, err := board.Record().Replay()
+	if err != nil {
+		t.Fatalf("err replaying: %v", err)
+	}
+	if !reflect.DeepEqual(replayed.Deck(), deck) || !replayed.GameOver() {
+		t.Fatalf("replayed deck: want:%v != got:%v", deck, replayed.Deck())
+	}
+}
+
+func TestDeckStatus(t *testing.T) {
+	board := NewBoard()
+	if err := board.SetDeck(Deck{3, 2, 2, 2, 2, 2, 2, 2, 2, 1}); err != nil {
+		t.Fatalf("err setting deck: %v", err)
+	}
+	for _, num := range []int{9, 0, 8} {
+		if err, _ := board.ApplyBestMove(num, 1); err != nil {
+			t.Fatalf("err applying best move: %v", err)
+		}
+	}
+	status := board.DeckStatus(7)
+	if want := []int{2, 2, 2, 2, 2, 2, 2, 2, 1, 0}; !reflect.DeepEqual(status.Remaining, want) {
+		t.Fatalf("remaining: want:%v != got:%v", want, status.Remaining)
+	}
+	if status.Left != 17 || status.Turns != 17 || status.HighLeft != 3 {
+		t.Fatalf("left:%v turns:%v high left:%v", status.Left, status.Turns, status.HighLeft)
+	}
+	total := 0.0
+	for _, p := range status.Next {
+		total += p
+	}
+	if math.Abs(total-1) > 1e-9 || status.Next[9] != 0 || status.Next[0] != 2.0/17 {
+		t.Fatalf("next: %v", status.Next)
+	}
+	var sb strings.Builder
+	status.Print(&sb)
+	if lines := strings.Count(sb.String(), "\n"); lines != 4 {
+		t.Fatalf("want 4 lines, got:\n%v", sb.String())
+	}
+}
+
+func TestDeckStatusEmpty(t *testing.T) {
+	board := NewBoard()
+	board.SetDeck(make(Deck, len(NUMBER)))
+	status := board.DeckStatus(7)
+	if status.Left != 0 || status.Turns != 0 || status.HighLeft != 0 {
+		t.Fatalf("empty deck: %+v", status)
+	}
+	for _, p := range status.Next {
+		if p != 0 {
+			t.Fatalf("next from an empty deck: %v", status.Next)
+		}
+	}
+}
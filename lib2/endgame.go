@@ -0,0 +1,139 @@
+package lib2
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+
+	endgame = the last few cards, where every number left in the deck is known from seen
+			  and the rest of the game can be searched completely
+	value = expected points still to come from a position: the next card is drawn with
+			probability copies left/cards left, and placed where it makes the most
+			(points now plus value of the position after), a card that fits nowhere is lost
+	memo = value of every position already worked out, keyed by flat and the cards left,
+		   the same position is reached by many draw orders
+
+	below the threshold ApplyBestMove and the lookahead/expectimax strategies switch to this
+	search, their values are exact there and a heuristic everywhere else
+
+*/
+
+// what the last search decided and whether its value can be trusted
+type SearchInfo struct {
+	Move  Move
+	Value float64 // points expected from this move to the end of the search
+	Exact bool    // Value is the exact expectation over every remaining draw order
+}
+
+// below this many cards left after the current one, searches are exact, 0 turns it off
+func (board *Board) SetEndgame(cards int) {
+	board.endgame = cards
+}
+
+func (board *Board) LastSearch() SearchInfo {
+	return board.lastSearch
+}
+
+// true if placing num leaves few enough cards for an exact search
+func (board *Board) inEndgame(num int) bool {
+	if board.endgame <= 0 || len(board.layers) == 0 {
+		return false
+	}
+	left := -1
+	for _, n := range board.Remaining() {
+		left += n
+	}
+	return left <= board.endgame
+}
+
+type endgame struct {
+	board *Board
+	memo  map[string]float64
+	nodes int
+}
+
+// exact best move for num, records it as the last search
+func (board *Board) solveEndgame(num int) (SearchInfo, error) {
+	remaining := board.Remaining()
+	remaining[num]--
+	e := &endgame{board: board, memo: map[string]float64{}}
+	moves := board.legalMovesOn(board.flat, num)
+	if len(moves) == 0 {
+		return SearchInfo{}, noValidMoves(num)
+	}
+	info := SearchInfo{Value: math.Inf(-1), Exact: true}
+	for _, m := range moves {
+		newFlat := copyFlat(board.flat)
+		board.placeOnFlat(newFlat, m)
+		if value := float64(board.score(num, m.Level)) + e.value(newFlat, remaining); value > info.Value {
+			info.Value = value
+			info.Move = m
+		}
+	}
+	board.fanout = []int{e.nodes}
+	board.lastSearch = info
+	return info, nil
+}
+
+func (e *endgame) key(flat *Layer, remaining []int) string {
+	key := make([]byte, 0, len(flat.cells)+len(remaining))
+	for _, level := range flat.cells {
+		key = append(key, byte(level))
+	}
+	for _, n := range remaining {
+		key = append(key, byte(n))
+	}
+	return string(key)
+}
+
+// expected points still to come on flat with remaining left to draw, remaining is restored
+func (e *endgame) value(flat *Layer, remaining []int) float64 {
+	total := 0
+	for _, n := range remaining {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	key := e.key(flat, remaining)
+	if v, ok := e.memo[key]; ok {
+		return v
+	}
+	e.nodes++
+	expected := 0.0
+	for num, n := range remaining {
+		if n == 0 {
+			continue
+		}
+		remaining[num]--
+		best := math.Inf(-1)
+		moves := e.board.legalMovesOn(flat, num)
+		if len(moves) == 0 {
+			best = float64(e.board.rules.Scoring.Unused) + e.value(flat, remaining)
+		}
+		for _, m := range moves {
+			value := float64(e.board.score(num, m.Level))
+			if total > 1 {
+				// the last card has nothing after it, no need to place it
+				newFlat := copyFlat(flat)
+				e.board.placeOnFlat(newFlat, m)
+				value += e.value(newFlat, remaining)
+			}
+			best = math.Max(best, value)
+		}
+		remaining[num]++
+		expected += float64(n) / float64(total) * best
+	}
+	e.memo[key] = expected
+	return expected
+}
+
+func (info SearchInfo) String() string {
+	kind := "heuristic"
+	if info.Exact {
+		kind = "exact"
+	}
+	return fmt.Sprintf("value %.2f (%v)", info.Value, kind)
+}
@@ -0,0 +1,75 @@
+package lib2
+
+import (
+	"math"
+	"testing"
+)
+
+// plays 0-6 so that only 7, 8 and 9 are left in a 1 copy deck
+func endgameBoard(t *testing.T) *Board {
+	board := newBoardRC(12, 12, 1)
+	for num := 0; num <= 6; num++ {
+		if err, _ := board.ApplyBestMove(num, 1); err != nil {
+			t.Fatalf("err applying best move: %v", err)
+		}
+	}
+	return board
+}
+
+func TestEndgameLastCardIsSolved(t *testing.T) {
+	board := endgameBoard(t)
+	board.ApplyBestMove(7, 1)
+	// 9 is the only card left after 8, so the game is fully known
+	sol, err := board.Solve([]int{8, 9}, SolveConfig{})
+	if err != nil {
+		t.Fatalf("err solving: %v", err)
+	}
+	board.SetEndgame(1)
+	if err, _ := board.ApplyBestMove(8, 1); err != nil {
+		t.Fatalf("err applying best move: %v", err)
+	}
+	info := board.LastSearch()
+	if !info.Exact {
+		t.Fatalf("search with 1 card left should be exact")
+	}
+	if info.Value != float64(sol.Value) {
+		t.Fatalf("value: want:%v != got:%v", sol.Value, info.Value)
+	}
+}
+
+func TestEndgameExpectation(t *testing.T) {
+	board := endgameBoard(t)
+	board.SetEndgame(2)
+	info, err := board.solveEndgame(7)
+	if err != nil {
+		t.Fatalf("err solving endgame: %v", err)
+	}
+	// knowing the order can only help, so the expectation can't beat the mean of
+	// the two orders solved with perfect information
+	a, _ := board.Solve([]int{7, 8, 9}, SolveConfig{})
+	b, _ := board.Solve([]int{7, 9, 8}, SolveConfig{})
+	if mean := float64(a.Value+b.Value) / 2; info.Value > mean+1e-9 {
+		t.Fatalf("expectation:%v > perfect information mean:%v", info.Value, mean)
+	}
+	if info.Value < float64(min(a.Value, b.Value))/2 || math.IsInf(info.Value, 0) {
+		t.Fatalf("expectation:%v is too low", info.Value)
+	}
+}
+
+func TestEndgameThreshold(t *testing.T) {
+	board := endgameBoard(t)
+	board.SetEndgame(1)
+	// 8 and 9 are left after 7, more than the threshold
+	if _, err := board.Play(LookaheadStrategy{Steps: 1}, 7); err != nil {
+		t.Fatalf("err playing: %v", err)
+	}
+	if board.LastSearch().Exact {
+		t.Fatalf("2 cards left is above the threshold, value should be a heuristic")
+	}
+	if _, err := board.Play(ExpectimaxStrategy{Steps: 1}, 9); err != nil {
+		t.Fatalf("err playing: %v", err)
+	}
+	if !board.LastSearch().Exact {
+		t.Fatalf("1 card left is within the threshold, value should be exact")
+	}
+}
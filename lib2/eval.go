@@ -0,0 +1,188 @@
+package lib2
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+/*
+
+	evaluation = guess of the points a position will still make, used where the search stops
+	plateau = connected cells of the flat that are all at the same level
+			  anything smaller than the smallest number can never be covered
+			......	plateaus:
+			.00011	  level 0: 6 cells
+			.00011	  level 1: 4 cells, a hole since every number covers at least 5
+			.00...
+	anchor = a valid placement of a remaining number above level 0, worth number*level
+	hole = cell that can never be covered: in a plateau smaller than the smallest number,
+		   or an empty pocket enclosed by tiles that is too small for any number
+
+	the evaluation is the weighted sum of the features below, every weight can be set
+
+*/
+
+type Weights struct {
+	Plateau   float64 // usable plateau cells, times level+1
+	Connected float64 // cells of the biggest plateau of each level, times level+1
+	Reach     float64 // best score each remaining card could make right now
+	Anchors   float64 // sqrt of anchors per level for each remaining card, times level
+	Holes     float64 // cells that can never be covered
+}
+
+// untuned guesses, see tune for better ones
+func DefaultWeights() Weights {
+	return Weights{Plateau: 0.02, Connected: 0.02, Reach: 0.25, Anchors: 0.1, Holes: -0.3}
+}
+
+type Features struct {
+	Plateau   float64
+	Connected float64
+	Reach     float64
+	Anchors   float64
+	Holes     float64
+}
+
+func (w Weights) apply(f Features) float64 {
+	return w.Plateau*f.Plateau + w.Connected*f.Connected + w.Reach*f.Reach + w.Anchors*f.Anchors + w.Holes*f.Holes
+}
+
+func LoadWeights(path string) (Weights, error) {
+	var w Weights
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return w, err
+	}
+	if err := json.Unmarshal(data, &w); err != nil {
+		return w, fmt.Errorf("error reading weights:%v: %v", path, err)
+	}
+	return w, nil
+}
+
+func (w Weights) Save(path string) error {
+	data, err := json.MarshalIndent(w, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// from now on the searches add the evaluation of the position where they stop,
+// nil goes back to scoring only the points made
+func (board *Board) SetWeights(w *Weights) {
+	board.weights = w
+}
+
+// evaluation of the current position with the board's weights (or the defaults)
+func (board *Board) Evaluate() float64 {
+	w := DefaultWeights()
+	if board.weights != nil {
+		w = *board.weights
+	}
+	return w.apply(board.features(board.flat, board.Remaining()))
+}
+
+func (board *Board) PrintEvaluation(w io.Writer) {
+	f := board.features(board.flat, board.Remaining())
+	fmt.Fprintf(w, "plateau %.0f  connected %.0f  reach %.0f  anchors %.1f  holes %.0f  => %.2f\n",
+		f.Plateau, f.Connected, f.Reach, f.Anchors, f.Holes, board.Evaluate())
+}
+
+func (board *Board) features(flat *Layer, remaining []int) Features {
+	f := Features{}
+	if flat.BB_TL_R > flat.BB_BR_R {
+		return f
+	}
+	R, C := board.R, board.C
+	minCells := board.rules.Shapes.minSize()
+	tlR, tlC, brR, brC := flat.BB_TL_R, flat.BB_TL_C, flat.BB_BR_R, flat.BB_BR_C
+	biggest := map[int8]int{}
+	// flood fill every plateau inside the bounding box
+	visited := make([]bool, R*C)
+	stack := []int{}
+	for r := tlR; r <= brR; r++ {
+		for c := tlC; c <= brC; c++ {
+			if visited[r*C+c] {
+				continue
+			}
+			level := flat.cells[r*C+c]
+			size, enclosed := 0, true
+			visited[r*C+c] = true
+			stack = append(stack[:0], r*C+c)
+			for len(stack) > 0 {
+				i := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				size++
+				ir, ic := i/C, i%C
+				if ir == tlR || ir == brR || ic == tlC || ic == brC {
+					enclosed = false
+				}
+				for _, d := range [4][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+					nr, nc := ir+d[0], ic+d[1]
+					if nr < tlR || nr > brR || nc < tlC || nc > brC {
+						continue
+					}
+					j := nr*C + nc
+					if !visited[j] && flat.cells[j] == level {
+						visited[j] = true
+						stack = append(stack, j)
+					}
+				}
+			}
+			if level == EMPTY {
+				// empty space outside the stack is not a hole, only enclosed pockets are
+				if enclosed && size < minCells {
+					f.Holes += float64(size)
+				}
+				continue
+			}
+			if size < minCells {
+				f.Holes += float64(size)
+				continue
+			}
+			f.Plateau += float64(size) * float64(level+1)
+			biggest[level] = max(biggest[level], size)
+		}
+	}
+	for level, size := range biggest {
+		f.Connected += float64(size) * float64(level+1)
+	}
+	for num, n := range remaining {
+		if n <= 0 {
+			continue
+		}
+		anchors := map[int8]int{}
+		best := 0
+		for _, m := range board.legalMovesOn(flat, num) {
+			if m.Level > 0 {
+				anchors[m.Level]++
+				best = max(best, board.score(num, m.Level))
+			}
+		}
+		f.Reach += float64(n * best)
+		for level, count := range anchors {
+			f.Anchors += float64(n) * float64(level) * math.Sqrt(float64(count))
+		}
+	}
+	return f
+}
+
+// evaluation of flat once m has been placed on it, seen already counts m
+func (board *Board) evaluateAfter(flat *Layer, m Move, seen []int, deck Deck) float64 {
+	remaining := make([]int, len(seen))
+	left := 0
+	for i, n := range seen {
+		remaining[i] = max(0, deck[i]-n)
+		left += remaining[i]
+	}
+	if left == 0 {
+		// game over, nothing left to make points with
+		return 0
+	}
+	newFlat := copyFlat(flat)
+	board.placeOnFlat(newFlat, m)
+	return board.weights.apply(board.features(newFlat, remaining))
+}
@@ -0,0 +1,127 @@
+package lib2
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestFeaturesPlateaus(t *testing.T) {
+	R, C := 6, 9
+	board := newBoardRC(R, C, 1)
+	board.putNumberAtLayer(0, 9, 0, 0, 1)
+	board.putNumberAtLayer(0, 5, 0, 1, 4)
+	/*
+		flat:
+		.000.....
+		.000000..
+		.00.000..
+		.00...0..
+		....000..
+	*/
+	remaining := make([]int, 10)
+	f := board.features(board.flat, remaining)
+	// one level 0 plateau covering both numbers
+	if want := float64(10 + 10); f.Plateau != want || f.Connected != want {
+		t.Fatalf("plateau: want:%v != got:%v,%v", want, f.Plateau, f.Connected)
+	}
+	if f.Holes != 0 || f.Reach != 0 || f.Anchors != 0 {
+		t.Fatalf("no holes and no cards left: got %+v", f)
+	}
+}
+
+func TestFeaturesHoles(t *testing.T) {
+	R, C := 6, 7
+	board := newBoardRC(R, C, 1)
+	board.putNumberAtLayer(0, 0, 0, 0, 1)
+	/*
+		flat:
+		.000...
+		.0.0...
+		.0.0...
+		.000...
+	*/
+	f := board.features(board.flat, make([]int, 10))
+	// the inside of the 0 is an enclosed pocket of 2 cells
+	if f.Holes != 2 {
+		t.Fatalf("holes: want:%v != got:%v", 2, f.Holes)
+	}
+	// 1 on top of the 0 leaves a level 0 sliver of 5 cells and a level 1 plateau of 5
+	board.putNumberAtLayer(1, 1, 0, 0, 2)
+	f = board.features(board.flat, make([]int, 10))
+	if f.Plateau != 5+2*5 {
+		board.printFlat()
+		t.Fatalf("plateau: want:%v != got:%v", 5+2*5, f.Plateau)
+	}
+}
+
+func TestFeaturesAnchors(t *testing.T) {
+	R, C := 4, 7
+	board := newBoardRC(R, C, 1)
+	board.putNumberAtLayer(0, 9, 0, 0, 1)
+	remaining := make([]int, 10)
+	remaining[8] = 1
+	f := board.features(board.flat, remaining)
+	// 8 fits on top of the 9 at (0,1) only
+	if f.Reach != 8 || f.Anchors != 1 {
+		t.Fatalf("reach,anchors: want:8,1 != got:%v,%v", f.Reach, f.Anchors)
+	}
+}
+
+func TestSearchUsesEvaluation(t *testing.T) {
+	R, C := 4, 7
+	board := newBoardRC(R, C, 1)
+	board.putNumberAtLayer(0, 9, 0, 0, 1)
+	seen := make([]int, 10)
+	seen[9] = 1
+	_, plain, err := board.findBestMoveV2(board.flat, seen, board.rules.Deck, 8, 1)
+	if err != nil {
+		t.Fatalf("err finding best move: %v", err)
+	}
+	board.SetWeights(&Weights{Holes: -10})
+	_, evaluated, err := board.findBestMoveV2(board.flat, seen, board.rules.Deck, 8, 1)
+	if err != nil {
+		t.Fatalf("err finding best move: %v", err)
+	}
+	// 8 on the 9 leaves slivers of the 9 uncovered, those are holes now
+	if evaluated >= plain {
+		t.Fatalf("holes should cost points: %v >= %v", evaluated, plain)
+	}
+}
+
+func TestWeightsSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "weights.json")
+	want := Weights{Plateau: 1, Connected: 2, Reach: 3, Anchors: 4, Holes: -5}
+	if err := want.Save(path); err != nil {
+		t.Fatalf("err saving weights: %v", err)
+	}
+	got, err := LoadWeights(path)
+	if err != nil {
+		t.Fatalf("err loading weights: %v", err)
+	}
+	if got != want {
+		t.Fatalf("want:%+v != got:%+v", want, got)
+	}
+}
+
+// the evaluation counts at every depth, a deeper search can't fall back to the
+// bare score of a move
+func TestSearchUsesEvaluationDeeper(t *testing.T) {
+	board := NewBoard()
+	for _, num := range []int{5, 9, 0} {
+		if err, _ := board.ApplyBestMove(num, 1); err != nil {
+			t.Fatalf("err applying best move: %v", err)
+		}
+	}
+	board.SetWeights(&Weights{Holes: -1000})
+	_, depth1, err := board.BestMove(8, 1)
+	if err != nil {
+		t.Fatalf("err finding best move: %v", err)
+	}
+	_, depth2, err := board.BestMove(8, 2)
+	if err != nil {
+		t.Fatalf("err finding best move: %v", err)
+	}
+	if depth1 >= 0 || depth2 >= 0 {
+		t.Fatalf("holes should cost points at every depth: depth 1:%v depth 2:%v", depth1, depth2)
+	}
+}
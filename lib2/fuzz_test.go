@@ -0,0 +1,116 @@
+package lib2
+
+import (
+	"strings"
+	"testing"
+)
+
+// rules from one byte: the orientations, adjacency and support
+func fuzzBoard(rows, cols, rules uint8) (*Board, error) {
+	return NewBoardWithRules(&Rules{
+		Name:         "fuzz",
+		R:            int(rows % 16),
+		C:            int(cols % 16),
+		Orientations: Orientations(rules % 4),
+		Adjacency:    Adjacency(rules / 4 % 2),
+		Support:      Support(rules / 8 % 2),
+	})
+}
+
+func FuzzApplyBestMove(f *testing.F) {
+	f.Add(uint8(12), uint8(12), uint8(0), []byte{9, 8, 7, 6, 5, 4})
+	f.Fuzz(func(t *testing.T, rows, cols, rules uint8, cards []byte) {
+		board, err := fuzzBoard(rows, cols, rules)
+		if err != nil {
+			return
+		}
+		for _, card := range cards[:min(len(cards), 6)] {
+			// -1 and 10 are not numbers, the top bit looks 2 steps ahead
+			num := int(card%128%12) - 1
+			placed := len(board.History())
+			err, _ := board.ApplyBestMove(num, 1+int(card>>7))
+			if err == nil && len(board.History()) != placed+1 {
+				t.Fatalf("num:%v applied without being placed", num)
+			}
+			if board.Score() < 0 {
+				t.Fatalf("score:%v below 0", board.Score())
+			}
+			if err := board.Validate(); err != nil {
+				t.Fatalf("num:%v: %v", num, err)
+			}
+			board.RenderIso()
+		}
+	})
+}
+
+// every move ApplyMove takes after the first has to be one LegalMoves offered
+func FuzzApplyMove(f *testing.F) {
+	f.Add(uint8(12), uint8(12), uint8(0), []byte{9, 0, 0, 0, 8, 4, 7, 0, 8, 4, 4, 0})
+	f.Fuzz(func(t *testing.T, rows, cols, rules uint8, moves []byte) {
+		board, err := fuzzBoard(rows, cols, rules)
+		if err != nil {
+			return
+		}
+		for i := 0; i+3 < len(moves) && i < 4*12; i += 4 {
+			m := Move{Num: int(int8(moves[i])), Row: int(int8(moves[i+1])), Col: int(int8(moves[i+2])), Orient: int(int8(moves[i+3]))}
+			first := len(board.History()) == 0
+			legal := board.LegalMoves(m.Num)
+			if err := board.ApplyMove(m); err != nil || first {
+				continue
+			}
+			placed := board.History()[len(board.History())-1]
+			found := false
+			for _, l := range legal {
+				found = found || l == placed
+			}
+			if !found {
+				t.Fatalf("%+v placed but not a legal move", placed)
+			}
+			if err := board.Validate(); err != nil {
+				t.Fatalf("%+v: %v", placed, err)
+			}
+			board.RenderIso()
+		}
+	})
+}
+
+// shapes files in the text format, played with every orientation
+func FuzzShapes(f *testing.F) {
+	f.Add("piece 7 3x3 value 7 copies 2\n###\n.#.\n#..\npiece 1 1x2 value 1 copies 3\n##\n", []byte{0, 1, 0, 1, 1})
+	f.Fuzz(func(t *testing.T, text string, cards []byte) {
+		shapes, err := parseShapes(text)
+		if err != nil {
+			return
+		}
+		set := &ShapeSet{Name: "fuzz", Shapes: shapes}
+		board, err := NewBoardWithShapes(8, 8, set)
+		if err != nil {
+			return
+		}
+		if board.SetOrientations(RotateFlip) != nil {
+			return
+		}
+		for _, card := range cards[:min(len(cards), 8)] {
+			num := int(card) % (len(shapes) + 1)
+			if err, _ := board.ApplyBestMove(num, 1); err != nil {
+				continue
+			}
+			if err := board.Validate(); err != nil {
+				t.Fatalf("num:%v: %v", num, err)
+			}
+			if iso := board.RenderIso(); strings.Count(iso, "\n") == 0 {
+				t.Fatalf("nothing rendered for %v placements", len(board.History()))
+			}
+		}
+	})
+}
+
+func FuzzColor(f *testing.F) {
+	f.Add(int8(9))
+	f.Fuzz(func(t *testing.T, num int8) {
+		if !strings.Contains(color(num, glyph(num)), glyph(num)) {
+			t.Fatalf("num:%v glyph missing from its colour", num)
+		}
+		isoGlyph(num, 1, true)
+	})
+}
@@ -0,0 +1,182 @@
+package lib2
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+/*
+
+	game record = everything needed to play a game again: board size, pieces, deck, the
+				  order the cards were drawn in and where every card that fit was placed
+	replay = the record played back move by move on a new board, every placement is
+			 checked so a record that was edited by hand can't produce an illegal board
+
+*/
+
+type GameRecord struct {
+	Rules        string `json:",omitempty"` // name of the rules the game started from
+	R            int
+	C            int
+	SeenLimit    int `json:",omitempty"` // copies of every number in records without a Deck
+	Deck         Deck
+	Shapes       *ShapeSet    // nil for the numbers 0-9
+	Scoring      *Scoring     // nil for the standard scoring
+	Orientations Orientations `json:",omitempty"` // fixed when missing
+	Support      Support      `json:",omitempty"` // level when missing
+	Adjacency    Adjacency    `json:",omitempty"` // bottom when missing
+	Cards        []int        // every card drawn, in order
+	Moves        []Move       // one placement per card that fit, in card order
+}
+
+// the game so far as a record
+func (board *Board) Record() *GameRecord {
+	rules := board.Rules()
+	rec := &GameRecord{Rules: rules.Name, R: rules.R, C: rules.C, Deck: rules.Deck, Shapes: rules.Shapes, Scoring: rules.Scoring,
+		Orientations: rules.Orientations, Support: rules.Support, Adjacency: rules.Adjacency}
+	rec.Cards = append([]int{}, board.drawn...)
+	rec.Moves = board.History()
+	return rec
+}
+
+func (board *Board) SaveGame(path string) error {
+	return board.Record().Save(path)
+}
+
+func (rec *GameRecord) Save(path string) error {
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func LoadGame(path string) (*GameRecord, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rec GameRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("error reading game:%v: %v", path, err)
+	}
+	if rec.Shapes != nil {
+		if err := rec.Shapes.validate(); err != nil {
+			return nil, fmt.Errorf("error reading game:%v: %v", path, err)
+		}
+	}
+	// a record edited by hand has to play back to a valid board
+	if _, err := rec.Replay(); err != nil {
+		return nil, fmt.Errorf("error reading game:%v: %v", path, err)
+	}
+	return &rec, nil
+}
+
+// a new board with the record played back on it, validated
+func (rec *GameRecord) Replay() (*Board, error) {
+	board, err := rec.replay()
+	if err != nil {
+		return nil, err
+	}
+	if err := board.Validate(); err != nil {
+		return nil, err
+	}
+	return board, nil
+}
+
+// the record played back without validating the board it makes
+func (rec *GameRecord) replay() (*Board, error) {
+	board, err := rec.NewBoard()
+	if err != nil {
+		return nil, err
+	}
+	for k := range rec.Cards {
+		if err := rec.replayCard(board, k); err != nil {
+			return nil, err
+		}
+	}
+	if placed := len(board.history); placed != len(rec.Moves) {
+		return nil, fmt.Errorf("game has %v moves but only %v match the cards", len(rec.Moves), placed)
+	}
+	return board, nil
+}
+
+// records num as drawn when it fits nowhere on the board, the card is lost
+func (board *Board) Discard(num int) error {
+	if err := board.discardStuck(num); err != nil {
+		return err
+	}
+	board.debugValidate()
+	return nil
+}
+
+// Discard without the debug check, replays validate once at the end
+func (board *Board) discardStuck(num int) error {
+	if err := board.checkNum(num); err != nil {
+		return err
+	}
+	if moves := board.LegalMoves(num); len(moves) > 0 {
+		return fmt.Errorf("num:%v fits in %v places and can't be discarded", num, len(moves))
+	}
+	board.discard(num)
+	return nil
+}
+
+// num was drawn but fits nowhere, it still counts as seen
+func (board *Board) discard(num int) {
+	board.drawn = append(board.drawn, num)
+	board.seen[num]++
+}
+
+// an empty board like the recorded one
+func (rec *GameRecord) NewBoard() (*Board, error) {
+	return NewBoardWithRules(rec.rules())
+}
+
+// the rules the record was played by
+func (rec *GameRecord) rules() *Rules {
+	rules := &Rules{Name: rec.Rules, R: rec.R, C: rec.C, Shapes: rec.Shapes, Orientations: rec.Orientations,
+		Support: rec.Support, Adjacency: rec.Adjacency, Deck: rec.Deck, Scoring: rec.Scoring}
+	if rules.Deck == nil && rules.Shapes == nil {
+		rules.Deck = UniformDeck(len(NUMBER), rec.SeenLimit)
+	}
+	return rules
+}
+
+// the move card k was placed with, false if it fit nowhere
+// board must hold the record played up to card k
+func (rec *GameRecord) moveFor(board *Board, k int) (Move, bool) {
+	next := len(board.history)
+	if next < len(rec.Moves) && rec.Moves[next].Num == rec.Cards[k] {
+		return rec.Moves[next], true
+	}
+	return Move{}, false
+}
+
+// plays card k of the record on board, which must hold the record up to card k
+func (rec *GameRecord) replayCard(board *Board, k int) error {
+	num := rec.Cards[k]
+	m, ok := rec.moveFor(board, k)
+	if !ok {
+		if err := board.discardStuck(num); err != nil {
+			return fmt.Errorf("card %v: %v", k+1, err)
+		}
+		return nil
+	}
+	if err := board.applyMove(m); err != nil {
+		return fmt.Errorf("card %v: %v", k+1, err)
+	}
+	placed := board.history[len(board.history)-1]
+	if placed.Row != m.Row || placed.Col != m.Col {
+		// only the first move can land somewhere else, it always goes in the middle
+		return fmt.Errorf("card %v: num:%v goes at (%v,%v), not (%v,%v)", k+1, num, placed.Row, placed.Col, m.Row, m.Col)
+	}
+	if placed.Level != m.Level {
+		return fmt.Errorf("card %v: num:%v at (%v,%v) is at level %v, not %v", k+1, num, m.Row, m.Col, placed.Level, m.Level)
+	}
+	if o, _ := board.canonical(num, m.Orient); placed.Orient != o {
+		return fmt.Errorf("card %v: num:%v is played in orientation:%v, not %v", k+1, num, placed.Orient, o)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package lib2
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestGameRecordRoundTrip(t *testing.T) {
+	board := NewBoard()
+	for _, num := range []int{5, 9, 0, 8, 1, 7} {
+		if err, _ := board.ApplyBestMove(num, 1); err != nil {
+			t.Fatalf("err applying best move: %v", err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "game.json")
+	if err := board.SaveGame(path); err != nil {
+		t.Fatalf("err saving game: %v", err)
+	}
+	rec, err := LoadGame(path)
+	if err != nil {
+		t.Fatalf("err loading game: %v", err)
+	}
+	replayed, err := rec.Replay()
+	if err != nil {
+		t.Fatalf("err replaying game: %v", err)
+	}
+	if replayed.Score() != board.Score() {
+		t.Fatalf("score: want:%v != got:%v", board.Score(), replayed.Score())
+	}
+	if want, got := board.RenderIso(), replayed.RenderIso(); want != got {
+		t.Fatalf("board:\n%v\n!= replayed:\n%v", want, got)
+	}
+}
+
+func TestReplayRejectsBadRecords(t *testing.T) {
+	board := NewBoard()
+	for _, num := range []int{5, 9, 0} {
+		board.ApplyBestMove(num, 1)
+	}
+	rec := board.Record()
+	rec.Moves[1].Level = 3
+	if _, err := rec.Replay(); err == nil {
+		t.Fatalf("replay should fail on a wrong level")
+	}
+	rec = board.Record()
+	// the corner is far from the stack, the 0 touches nothing there
+	rec.Moves[2].Row = board.R - 4
+	rec.Moves[2].Col = board.C - 3
+	if _, err := rec.Replay(); err == nil {
+		t.Fatalf("replay should fail on a move that doesn't touch anything")
+	}
+	rec = board.Record()
+	// the first number always goes in the middle
+	rec.Moves[0].Row++
+	if _, err := rec.Replay(); err == nil {
+		t.Fatalf("replay should fail on a first move that isn't in the middle")
+	}
+	rec = board.Record()
+	rec.Moves = rec.Moves[:2]
+	if _, err := rec.Replay(); err == nil {
+		t.Fatalf("replay should fail on a card without a move that fits somewhere")
+	}
+}
+
+func TestLoadGameRejectsTamperedRecord(t *testing.T) {
+	board := NewBoard()
+	for _, num := range []int{5, 9, 0} {
+		board.ApplyBestMove(num, 1)
+	}
+	rec := board.Record()
+	// the corner is far from the stack, the 0 touches nothing there
+	rec.Moves[2].Row = board.R - 4
+	rec.Moves[2].Col = board.C - 3
+	path := filepath.Join(t.TempDir(), "game.json")
+	if err := rec.Save(path); err != nil {
+		t.Fatalf("err saving game: %v", err)
+	}
+	if _, err := LoadGame(path); err == nil {
+		t.Fatalf("loading should fail on a move that doesn't touch anything")
+	}
+	if _, err := Analyze(rec, DefaultAnalyzeConfig()); err == nil {
+		t.Fatalf("analyzing should fail on a move that doesn't touch anything")
+	}
+	rec = board.Record()
+	rec.Moves = append(rec.Moves, Move{Num: 8})
+	if _, err := Analyze(rec, DefaultAnalyzeConfig()); err == nil {
+		t.Fatalf("analyzing should fail on a move without a card")
+	}
+}
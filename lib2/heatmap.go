@@ -0,0 +1,178 @@
+package lib2
+
+import (
+	"fmt"
+	"strings"
+
+	"nmbr9/core"
+)
+
+/*
+
+	heatmap = every anchor num can be placed at in one orientation, what level it would
+			  land on and what the lookahead thinks of it, for deciding by hand
+				level		the level the piece would lie on
+				value		its score plus the best the next steps-1 cards can add, the
+							same value ApplyBestMove picks the highest of
+	terminal = two grids side by side, level then value, anchors coloured, the cells
+			   already covered as walls
+				level:		value:
+				..1100..	..9800..
+				..▒▒▒▒0.	..▒▒▒▒3.
+	svg = the same grid as a picture, every anchor filled by value with its level written
+		  in it and outlined in the colour of the level, covered cells gray by level
+
+*/
+
+// a legal move and what the lookahead thinks of it
+type MoveValue = core.MoveValue
+
+type Heatmap struct {
+	R      int
+	C      int
+	Num    int
+	Orient int
+	Steps  int
+	Flat   []int8      // the level of every cell row after row, EMPTY where there is none
+	Moves  []MoveValue // one per legal anchor
+}
+
+// every legal anchor of num in orientation o with its level and its value over the
+// next steps cards
+func (board *Board) Heatmap(num, o, steps int) (*Heatmap, error) {
+	if err := board.checkNum(num); err != nil {
+		return nil, err
+	}
+	o, err := board.canonical(num, o)
+	if err != nil {
+		return nil, err
+	}
+	if steps < 1 {
+		return nil, fmt.Errorf("steps:%v must be at least 1", steps)
+	}
+	h := &Heatmap{R: board.R, C: board.C, Num: num, Orient: o, Steps: steps, Flat: append([]int8{}, board.flat.cells...)}
+	if len(board.layers) == 0 {
+		// the first number goes in the middle, there is nothing to look ahead from
+		r, c := board.middle(num, o)
+		h.Moves = []MoveValue{{Move: Move{Num: num, Row: r, Col: c, Orient: o}, Value: board.score(num, 0)}}
+		return h, nil
+	}
+	seen := make([]int, len(board.seen))
+	copy(seen, board.seen)
+	board.fanout = make([]int, steps)
+	h.Moves = board.lookahead(board.rules.Deck).MoveValues(board.flat, seen, board.rules.Deck, num, o, steps)
+	return h, nil
+}
+
+// lowest and highest value of any anchor, 0 and 0 when there are none
+func (h *Heatmap) Range() (int, int) {
+	if len(h.Moves) == 0 {
+		return 0, 0
+	}
+	lo, hi := h.Moves[0].Value, h.Moves[0].Value
+	for _, m := range h.Moves {
+		lo, hi = min(lo, m.Value), max(hi, m.Value)
+	}
+	return lo, hi
+}
+
+// the anchors by cell, nil where num can't go
+func (h *Heatmap) anchors() []*MoveValue {
+	cells := make([]*MoveValue, h.R*h.C)
+	for i := range h.Moves {
+		m := &h.Moves[i]
+		cells[m.Row*h.C+m.Col] = m
+	}
+	return cells
+}
+
+// value on a scale of 0 to 9 between the lowest and highest, 9 if they are the same
+func (h *Heatmap) heat(value int) int {
+	lo, hi := h.Range()
+	if lo == hi {
+		return 9
+	}
+	return (value - lo) * 9 / (hi - lo)
+}
+
+var levelColors = []string{Green, Yellow, Orange, Red, Magenta}
+
+// cold to hot
+var heatColors = []string{Blue, Blue, Cyan, Cyan, Green, Green, Yellow, Yellow, Orange, Red}
+
+// the level and value grids side by side with a legend underneath
+func (h *Heatmap) Render() string {
+	lo, hi := h.Range()
+	anchors := h.anchors()
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%-*v   %v\n", h.C, "level:", "value:")
+	for r := 0; r < h.R; r++ {
+		for grid := 0; grid < 2; grid++ {
+			for c := 0; c < h.C; c++ {
+				m := anchors[r*h.C+c]
+				switch {
+				case m != nil && grid == 0:
+					sb.WriteString(levelColors[int(m.Level)%len(levelColors)] + fmt.Sprint(m.Level) + Reset)
+				case m != nil:
+					heat := h.heat(m.Value)
+					if m.Value == hi {
+						sb.WriteString(Bold)
+					}
+					sb.WriteString(heatColors[heat] + fmt.Sprint(heat) + Reset)
+				case h.Flat[r*h.C+c] != EMPTY:
+					sb.WriteString(Purple + string(isoWall) + Reset)
+				default:
+					sb.WriteString(".")
+				}
+			}
+			if grid == 0 {
+				sb.WriteString("   ")
+			}
+		}
+		sb.WriteString("\n")
+	}
+	fmt.Fprintf(&sb, "num:%v orient:%v steps:%v  %v anchors", h.Num, h.Orient, h.Steps, len(h.Moves))
+	if len(h.Moves) > 0 {
+		fmt.Fprintf(&sb, "  value 0=%v 9=%v", lo, hi)
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+const svgCell = 24
+
+var svgLevelColors = []string{"#2e7d32", "#f9a825", "#ef6c00", "#c62828", "#6a1b9a"}
+
+// the heatmap as a standalone svg picture
+func (h *Heatmap) SVG() string {
+	lo, hi := h.Range()
+	anchors := h.anchors()
+	W, H := h.C*svgCell, h.R*svgCell+svgCell
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%v\" height=\"%v\" viewBox=\"0 0 %v %v\" font-family=\"monospace\" font-size=\"12\">\n", W, H, W, H)
+	fmt.Fprintf(&sb, "<rect width=\"%v\" height=\"%v\" fill=\"white\"/>\n", W, H)
+	for r := 0; r < h.R; r++ {
+		for c := 0; c < h.C; c++ {
+			x, y := c*svgCell, r*svgCell
+			m := anchors[r*h.C+c]
+			switch {
+			case m != nil:
+				// blue for the lowest value to red for the highest
+				hue := 240 - 240*h.heat(m.Value)/9
+				level := svgLevelColors[int(m.Level)%len(svgLevelColors)]
+				fmt.Fprintf(&sb, "<rect x=\"%v\" y=\"%v\" width=\"%v\" height=\"%v\" fill=\"hsl(%v,80%%,60%%)\" stroke=\"%v\" stroke-width=\"2\"><title>(%v,%v) level %v value %v</title></rect>\n",
+					x+1, y+1, svgCell-2, svgCell-2, hue, level, r, c, m.Level, m.Value)
+				fmt.Fprintf(&sb, "<text x=\"%v\" y=\"%v\" text-anchor=\"middle\">%v</text>\n", x+svgCell/2, y+svgCell*2/3, m.Level)
+			case h.Flat[r*h.C+c] != EMPTY:
+				gray := max(0x40, 0xc0-0x30*int(h.Flat[r*h.C+c]))
+				fmt.Fprintf(&sb, "<rect x=\"%v\" y=\"%v\" width=\"%v\" height=\"%v\" fill=\"#%02x%02x%02x\"/>\n", x, y, svgCell, svgCell, gray, gray, gray)
+			default:
+				fmt.Fprintf(&sb, "<rect x=\"%v\" y=\"%v\" width=\"%v\" height=\"%v\" fill=\"none\" stroke=\"#eeeeee\"/>\n", x, y, svgCell, svgCell)
+			}
+		}
+	}
+	fmt.Fprintf(&sb, "<text x=\"2\" y=\"%v\">num %v orient %v steps %v, %v anchors, value %v to %v</text>\n",
+		h.R*svgCell+svgCell*2/3, h.Num, h.Orient, h.Steps, len(h.Moves), lo, hi)
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
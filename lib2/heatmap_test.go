@@ -0,0 +1,90 @@
+package lib2
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestHeatmapMatchesSearch(t *testing.T) {
+	board := NewBoard()
+	for _, num := range []int{5, 9, 0, 8} {
+		board.ApplyBestMove(num, 1)
+	}
+	for _, steps := range []int{1, 2} {
+		h, err := board.Heatmap(7, 0, steps)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		// the same scan, so the same order
+		if got, want := fmt.Sprint(anchorMoves(h)), fmt.Sprint(board.LegalMoves(7)); got != want {
+			t.Fatalf("steps:%v anchors:\n%v\n!= legal moves:\n%v", steps, got, want)
+		}
+		best, score, err := board.BestMove(7, steps)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		if _, hi := h.Range(); hi != score {
+			t.Fatalf("steps:%v highest value:%v != best score:%v", steps, hi, score)
+		}
+		for _, m := range h.Moves {
+			if m.Move == best && m.Value != score {
+				t.Fatalf("steps:%v best move %+v valued %v, not %v", steps, best, m.Value, score)
+			}
+		}
+	}
+}
+
+func anchorMoves(h *Heatmap) []Move {
+	moves := make([]Move, len(h.Moves))
+	for i, m := range h.Moves {
+		moves[i] = m.Move
+	}
+	return moves
+}
+
+func TestHeatmapFirstMove(t *testing.T) {
+	board := NewBoard()
+	h, err := board.Heatmap(8, 0, 2)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	if len(h.Moves) != 1 || h.Moves[0].Move != board.LegalMoves(8)[0] {
+		t.Fatalf("first move: %+v", h.Moves)
+	}
+}
+
+func TestHeatmapErrors(t *testing.T) {
+	board := NewBoard()
+	if _, err := board.Heatmap(10, 0, 1); err == nil {
+		t.Fatalf("10 is not a number")
+	}
+	if _, err := board.Heatmap(8, 1, 1); err == nil {
+		t.Fatalf("fixed rules can't turn pieces")
+	}
+	if _, err := board.Heatmap(8, 0, 0); err == nil {
+		t.Fatalf("0 steps should be an error")
+	}
+}
+
+func TestHeatmapRender(t *testing.T) {
+	board := NewBoard()
+	for _, num := range []int{5, 9} {
+		board.ApplyBestMove(num, 1)
+	}
+	h, err := board.Heatmap(0, 0, 1)
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	text := h.Render()
+	if lines := strings.Count(text, "\n"); lines != board.R+2 {
+		t.Fatalf("want %v lines, got %v:\n%v", board.R+2, lines, text)
+	}
+	svg := h.SVG()
+	if !strings.HasPrefix(svg, "<svg") || !strings.HasSuffix(svg, "</svg>\n") {
+		t.Fatalf("not an svg:\n%v", svg)
+	}
+	if n := strings.Count(svg, "<title>"); n != len(h.Moves) {
+		t.Fatalf("svg has %v anchors, want %v", n, len(h.Moves))
+	}
+}
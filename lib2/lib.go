@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"math"
 	"strings"
+
+	"nmbr9/core"
 )
 
 /*
@@ -54,14 +56,22 @@ var NUMBER = [][]int8{
 }
 
 type Board struct {
-	R         int
-	C         int
-	layers    []*Layer
-	flat      *Layer
-	seen      []int
-	seenLimit int
-	fanout    []int
-}
+	R          int
+	C          int
+	layers     []*Layer
+	flat       *Layer
+	seen       []int
+	fanout     []int
+	history    []Move
+	drawn      []int    // every card drawn, including ones that fit nowhere
+	rules      Rules    // Shapes, Deck and Scoring are always set
+	weights    *Weights // evaluation added where the search stops, nil to skip
+	endgame    int      // cards left below which searches are exact, 0 to skip
+	lastSearch SearchInfo
+}
+
+// a single placement of num in orientation Orient with its top left corner at (Row,Col)
+type Move = core.Move
 
 type Layer struct {
 	R       int
@@ -83,14 +93,66 @@ func NewBoard() *Board {
 
 func newBoardRC(rows, cols int, seenLimit int) *Board {
 	return &Board{
-		R:         rows,
-		C:         cols,
-		layers:    make([]*Layer, 0, 20),
-		flat:      makeFlatRC(rows, cols),
-		seen:      make([]int, 10),
-		seenLimit: seenLimit,
-		fanout:    make([]int, 10),
+		R:       rows,
+		C:       cols,
+		layers:  make([]*Layer, 0, 20),
+		flat:    makeFlatRC(rows, cols),
+		seen:    make([]int, 10),
+		fanout:  make([]int, 10),
+		history: make([]Move, 0, 20),
+		drawn:   make([]int, 0, 20),
+		rules: Rules{
+			Name:    "official",
+			R:       rows,
+			C:       cols,
+			Shapes:  StandardShapes(seenLimit),
+			Deck:    UniformDeck(len(NUMBER), seenLimit),
+			Scoring: StandardScoring(),
+		},
+	}
+}
+
+// a board played with set instead of the numbers 0-9
+func NewBoardWithShapes(rows, cols int, set *ShapeSet) (*Board, error) {
+	if err := set.validate(); err != nil {
+		return nil, err
+	}
+	if err := set.fits(rows, cols); err != nil {
+		return nil, err
 	}
+	board := newBoardRC(rows, cols, 0)
+	board.seen = make([]int, len(set.Shapes))
+	board.rules.Name = set.Name
+	board.rules.Shapes = set
+	board.rules.Deck = make(Deck, len(set.Shapes))
+	for num, shape := range set.Shapes {
+		board.rules.Deck[num] = shape.Copies
+	}
+	return board, nil
+}
+
+func (board *Board) Shapes() *ShapeSet {
+	return board.rules.Shapes
+}
+
+func (board *Board) shape(num int) *Shape {
+	return board.rules.Shapes.Shapes[num]
+}
+
+// copies of num in the deck
+func (board *Board) copies(num int) int {
+	return board.rules.Deck[num]
+}
+
+// error if num is not a piece or all its copies have been placed
+func (board *Board) checkNum(num int) error {
+	if num < 0 || num >= len(board.rules.Shapes.Shapes) {
+		return fmt.Errorf("num:%v is not a number", num)
+	}
+	if board.seen[num] >= board.copies(num) {
+		return &SeenError{Num: num, Copies: board.copies(num)}
+	}
+	return nil
 }
 
 func (board *Board) addLayer() *Layer {
@@ -99,44 +161,127 @@ func (board *Board) addLayer() *Layer {
 	return layer
 }
 
-func (board *Board) putNumberAtLayer(level int8, num, row, col int) {
-	if int(level) >= len(board.layers) {
+// error if num doesn't fit at (row,col) or level would leave a level empty under it,
+// nothing is placed then
+func (board *Board) putNumberAtLayer(level int8, num, o, row, col int) error {
+	if level < 0 || int(level) > len(board.layers) {
+		return fmt.Errorf("num:%v can't go on level:%v of a board with %v levels", num, level, len(board.layers))
+	}
+	if !board.isInBounds(num, o, row, col) {
+		return fmt.Errorf("num:%v can't be placed at (%v,%v)", num, row, col)
+	}
+	if int(level) == len(board.layers) {
 		board.addLayer()
 	}
 	layer := board.layers[level]
-	board.putNumber(layer, num, row, col)
-	board.flat = board.flatten(board.flat, layer, level)
+	if err := board.putNumber(layer, num, o, row, col); err != nil {
+		return err
+	}
+	// only the new piece, flattening all of layer would bury tiles stacked on it
+	core.Place(board.flat, board.orient(num, o), row, col, level)
 	board.seen[num]++
+	board.history = append(board.history, Move{Num: num, Row: row, Col: col, Level: level, Orient: o})
+	board.drawn = append(board.drawn, num)
+	return nil
 }
 
-func (board *Board) setBaseLayer(num int) {
+func (board *Board) setBaseLayer(num, o int) error {
 	// put base layer number in the middle since board should be empty
-	NR, NC := getNumberSize()
-	midR := (board.R - NR) / 2
-	midC := (board.C - NC) / 2
-	board.putNumberAtLayer(0, num, midR, midC)
+	midR, midC := board.middle(num, o)
+	return board.putNumberAtLayer(0, num, o, midR, midC)
+}
+
+// top left corner that puts num in orientation o in the middle of the board
+func (board *Board) middle(num, o int) (int, int) {
+	NR, NC := board.orient(num, o).Rows, board.orient(num, o).Cols
+	return (board.R - NR) / 2, (board.C - NC) / 2
 }
 
 // steps: how many steps to look ahead
 // limit: how many steps until game ends
 func (board *Board) ApplyBestMove(num int, steps int) (error, int) {
+	err, score := board.applyBestMove(num, steps)
+	if err == nil {
+		board.debugValidate()
+	}
+	return err, score
+}
+
+// ApplyBestMove without the debug check
+func (board *Board) applyBestMove(num int, steps int) (error, int) {
+	if err := board.checkNum(num); err != nil {
+		return err, 0
+	}
 	if len(board.layers) == 0 {
-		board.setBaseLayer(num)
-		return nil, 0
+		board.fanout = make([]int, steps)
+		return board.setBaseLayer(num, 0), 0
 	} else {
-		if board.seen[num] >= board.seenLimit {
-			return fmt.Errorf("num:%v has already been seen limit:%v times", num, board.seenLimit), 0
+		if board.inEndgame(num) {
+			info, err := board.solveEndgame(num)
+			if err != nil {
+				board.discard(num)
+				return err, 0
+			}
+			if err := board.putNumberAtLayer(info.Move.Level, num, info.Move.Orient, info.Move.Row, info.Move.Col); err != nil {
+				return err, 0
+			}
+			return nil, int(math.Round(info.Value))
 		}
 		board.fanout = make([]int, steps)
-		bestR, bestC, bestLevel, maxScore, err := board.findBestMoveV2(board.flat, board.seen, board.seenLimit, num, steps)
+		best, maxScore, err := board.findBestMoveV2(board.flat, board.seen, board.rules.Deck, num, steps)
 		if err != nil {
+			board.discard(num)
+			return err, 0
+		}
+		board.lastSearch = SearchInfo{Move: best, Value: float64(maxScore)}
+		if err := board.putNumberAtLayer(best.Level, num, best.Orient, best.Row, best.Col); err != nil {
 			return err, 0
 		}
-		board.putNumberAtLayer(bestLevel, num, bestR, bestC)
 		return nil, maxScore
 	}
 }
 
+// the move the lookahead of ApplyBestMove picks for num and its score, without
+// making it, the endgame solver is left out
+func (board *Board) BestMove(num int, steps int) (Move, int, error) {
+	if err := board.checkNum(num); err != nil {
+		return Move{}, 0, err
+	}
+	if len(board.layers) == 0 {
+		return board.legalMovesOn(board.flat, num)[0], 0, nil
+	}
+	seen := make([]int, len(board.seen))
+	copy(seen, board.seen)
+	board.fanout = make([]int, steps)
+	return board.findBestMoveV2(board.flat, seen, board.rules.Deck, num, steps)
+}
+
+// sum of the scores of every number placed so far, plus the scoring's
+// points for every card that fit nowhere
+func (board *Board) Score() int {
+	total := 0
+	for _, m := range board.history {
+		total += board.score(m.Num, m.Level)
+	}
+	return total + board.rules.Scoring.Unused*(len(board.drawn)-len(board.history))
+}
+
+// every placement so far, in the order they were made
+func (board *Board) History() []Move {
+	history := make([]Move, len(board.history))
+	copy(history, board.history)
+	return history
+}
+
+// how many positions the last search looked at
+func (board *Board) Nodes() int {
+	nodes := 0
+	for _, n := range board.fanout {
+		nodes += n
+	}
+	return nodes
+}
+
 func (board *Board) PrintOverlays(showBB bool) {
 	overlays := make([]*Layer, len(board.layers))
 	lay := makeLayerRC(board.R, board.C)
@@ -165,84 +310,46 @@ func max(a, b int) int {
 	return b
 }
 
-func (board *Board) findBestMoveV2(flat *Layer, seen []int, seenLimit int, num int, steps int) (int, int, int8, int, error) {
-	board.fanout[len(board.fanout)-steps]++
-	maxScore := -10000
-	R, C := board.R, board.C
-	NR, NC := getNumberSize()
-	hasValid := false
-	var bestR, bestC int
-	var bestLevel int8
-	// for every (r,c) check if num can be placed there in a valid way
-	delta := 0
-	startR := max(0, flat.BB_TL_R-NR) + delta
-	startC := max(0, flat.BB_TL_C-NC) + delta
-	endR := min(R-1, flat.BB_BR_R+NR) - delta
-	endC := min(C-1, flat.BB_BR_C+NC) - delta
-	for r := startR; r <= endR; r++ {
-		for c := startC; c <= endC; c++ {
-			// for r := 0; r < R; r++ {
-			// for c := 0; c < C; c++ {
-			if valid, level := board.isValid(flat, num, r, c); valid {
-				hasValid = true
-				// score this move
-				newScore := score(num, level)
-				if newScore > maxScore {
-					maxScore = newScore
-					bestR, bestC = r, c
-					bestLevel = level
-				}
-				if steps > 1 {
-					// apply move
-					layer := makeLayerRC(board.R, board.C)
-					board.putNumber(layer, num, r, c)
-					seen[num]++
-					// compute new flat
-					newFlat := copyFlat(flat)
-					board.flatten(newFlat, layer, level)
-					// recursively find best move
-					for s := 0; s < seenLimit; s++ {
-						for i := range seen {
-							if seen[i] < seenLimit {
-								_, _, _, futureScore, err := board.findBestMoveV2(newFlat, seen, seenLimit, i, steps-1)
-								if err == nil && newScore+futureScore > maxScore {
-									maxScore = newScore + futureScore
-									bestR, bestC = r, c
-									bestLevel = level
-								}
-							}
-						}
-					}
-					// undo move to backtrack
-					seen[num]--
-				}
-			}
-		}
+// the placement rules and the searches of core, played with the board's pieces,
+// rules and scoring
+func (board *Board) search() *core.Search {
+	return &core.Search{
+		Rules:   board.placement(),
+		Orients: board.orients,
+		Piece:   board.orient,
+		Score:   board.score,
 	}
-	if !hasValid {
-		return 0, 0, 0, 0, fmt.Errorf("no valid moves for num: %v", num)
+}
+
+func (board *Board) placement() core.Placement {
+	return core.Placement{
+		EveryLevel: board.rules.Adjacency == TouchEveryLevel,
+		BottomOnly: board.rules.Support == SupportBottom,
 	}
-	return bestR, bestC, bestLevel, maxScore, nil
 }
 
-func (board *Board) findBestMove(flat *Layer, num int) (int, int, int8) {
-	R, C := board.R, board.C
-	maxScore := -1
-	var bestR, bestC int
-	var bestLevel int8
-	for r := 0; r < R; r++ {
-		for c := 0; c < C; c++ {
-			if valid, level := board.isValid(flat, num, r, c); valid {
-				newScore := score(num, level)
-				if newScore > maxScore {
-					maxScore = newScore
-					bestR, bestC = r, c
-					bestLevel = level
-				}
-			}
+func (board *Board) findBestMoveV2(flat *Layer, seen []int, deck Deck, num int, steps int) (Move, int, error) {
+	return board.lookahead(deck).BestMove(flat, seen, deck, num, steps)
+}
+
+// the search of the lookahead, counting fanout and adding the weights where it stops
+// fanout needs an entry per step
+func (board *Board) lookahead(deck Deck) *core.Search {
+	s := board.search()
+	s.Visit = func(steps int) {
+		board.fanout[len(board.fanout)-steps]++
+	}
+	if board.weights != nil {
+		// horizon: also count what the position is worth
+		s.Horizon = func(flat core.Storage, m Move, seen []int) int {
+			return int(math.Round(board.evaluateAfter(flat.(*Layer), m, seen, deck)))
 		}
 	}
-	return bestR, bestC, bestLevel
+	return s
+}
+
+func (board *Board) findBestMove(flat *Layer, num int) Move {
+	return board.search().Greedy(flat, num)
 }
 
 func copyLayer(layer *Layer) *Layer {
@@ -263,134 +370,19 @@ func copyFlat(flat *Layer) *Layer {
 	}
 }
 
-func score(num int, level int8) int {
-	return num * int(level)
+// check if num in orientation o can be placed at (row,col) using flat
+func (board *Board) isValid(flat *Layer, num, o int, row, col int) (bool, int8) {
+	return core.Valid(flat, board.orient(num, o), row, col, board.placement())
 }
 
-// check if num can be placed at (row,col) using flat
-func (board *Board) isValid(flat *Layer, num int, row, col int) (bool, int8) {
-	if !board.isInBounds(row, col) {
-		return false, 0
-	}
-	// validity requires that every non-empty cell needs to be placed
-	// on top of the same level number
-	same, level := board.isOnSameLevel(flat, num, row, col)
-	if !same {
-		return false, 0
-	}
-	// if we're placing at the bottom layer, validity also requires that num is
-	// touching an existing already placed number
-	if level == 0 && !board.isTouching(flat, num, row, col) {
-		return false, 0
-	}
-	return true, level
-}
-
-func (board *Board) isInBounds(row, col int) bool {
-	NR, NC := getNumberSize()
-	R, C := board.R, board.C
-	/*
-		000
-		000 (row,col) = (1,1)
-		000 (R,C) = (3,3)
-
-		11
-		11 (NR,NC) = (2,2)
-
-		000
-		011 return 1+2-1<3 && 1+2-1<3 = true
-		011
-	*/
-	return row+NR-1 < R && col+NC-1 < C
-}
-
-// true if num, when placed at (row,col) is touching an existing number in flat
-func (board *Board) isTouching(flat *Layer, num int, row, col int) bool {
-	n := NUMBER[num]
-	NR, NC := getNumberSize()
-	R, C := board.R, board.C
-	touching := false
-outer:
-	for r := row; r < row+NR && r < R; r++ {
-		for c := col; c < col+NC && c < C; c++ {
-			// number cell is not EMPTY
-			if n[((r-row)*NC)+(c-col)] != EMPTY {
-				// top neighbor
-				if r-1 >= 0 && flat.cells[(r-1)*C+c] >= 0 {
-					touching = true
-					break outer
-				}
-				// bottom neighbor
-				if r+1 < R && flat.cells[(r+1)*C+c] >= 0 {
-					touching = true
-					break outer
-				}
-				// left neighbor
-				if c-1 >= 0 && flat.cells[r*C+(c-1)] >= 0 {
-					touching = true
-					break outer
-				}
-				// right neighbor
-				if c+1 < C && flat.cells[r*C+(c+1)] >= 0 {
-					touching = true
-					break outer
-				}
-			}
-		}
-	}
-	return touching
-}
-
-func (board *Board) isOnSameLevel(flat *Layer, num int, row, col int) (bool, int8) {
-	n := NUMBER[num]
-	NR, NC := getNumberSize()
-	R, C := board.R, board.C
-	unset := true
-	var fval int8 = -1
-	// validity requires that every non-empty cell needs to be placed
-	// on top of the same level number
-	for r := row; r < row+NR && r < R; r++ {
-		for c := col; c < col+NC && c < C; c++ {
-			if n[(r-row)*NC+(c-col)] >= 0 { // if num has non-empty cell...
-				if unset {
-					fval = flat.cells[r*C+c]
-					unset = false
-				} else {
-					if fval != flat.cells[r*C+c] { // if num crosses levels...
-						return false, 0
-					}
-				}
-			}
-		}
-	}
-	level := fval + 1
-	return true, level
+func (board *Board) isInBounds(num, o int, row, col int) bool {
+	return core.InBounds(board.flat, board.orient(num, o), row, col)
 }
 
 // merges layer onto flat, modifying flat
 // level is the level of layer
 func (board *Board) flatten(flat, layer *Layer, level int8) *Layer {
-	R, C := board.R, board.C
-	for r := 0; r < R; r++ {
-		for c := 0; c < C; c++ {
-			if layer.cells[r*C+c] != EMPTY {
-				flat.cells[r*C+c] = level
-			}
-		}
-	}
-	// update bounding box of flat
-	if layer.BB_TL_R < flat.BB_TL_R {
-		flat.BB_TL_R = layer.BB_TL_R
-	}
-	if layer.BB_TL_C < flat.BB_TL_C {
-		flat.BB_TL_C = layer.BB_TL_C
-	}
-	if layer.BB_BR_R > flat.BB_BR_R {
-		flat.BB_BR_R = layer.BB_BR_R
-	}
-	if layer.BB_BR_C > flat.BB_BR_C {
-		flat.BB_BR_C = layer.BB_BR_C
-	}
+	flat.Flatten(layer, level)
 	return flat
 }
 
@@ -419,32 +411,8 @@ func (board *Board) overlay(layer1, layer2 *Layer) {
 	}
 }
 
-func (board *Board) putNumber(layer *Layer, num, row, col int) {
-	n := NUMBER[num]
-	NR, NC := getNumberSize()
-	for i := row; i < row+NR; i++ {
-		for j := col; j < col+NC; j++ {
-			nn := n[(i-row)*NC+(j-col)]
-			if nn != EMPTY {
-				layer.cells[i*board.C+j] = nn
-			}
-		}
-	}
-	// update bounding box of layer
-	if row < layer.BB_TL_R {
-		layer.BB_TL_R = row
-	}
-	if col < layer.BB_TL_C {
-		layer.BB_TL_C = col
-	}
-	brr := row + NR - 1
-	if brr > layer.BB_BR_R {
-		layer.BB_BR_R = brr
-	}
-	brc := col + NC - 1
-	if brc > layer.BB_BR_C {
-		layer.BB_BR_C = brc
-	}
+func (board *Board) putNumber(layer *Layer, num, o, row, col int) error {
+	return core.Put(layer, board.orient(num, o), row, col)
 }
 
 func makeFlatRC(rows, cols int) *Layer {
@@ -467,10 +435,6 @@ func makeLayerRC(rows, cols int) *Layer {
 	}
 }
 
-func getNumberSize() (int, int) {
-	return 4, 3 // hardcoded
-}
-
 func (board *Board) printLayer(layer *Layer) {
 	board.printLayers([]*Layer{layer})
 }
@@ -501,7 +465,7 @@ func (board *Board) printLayersBB(layers []*Layer, showBB bool) {
 					if layer.cells[r*C+c] == EMPTY {
 						fmt.Printf(".")
 					} else {
-						fmt.Printf(color(layer.cells[r*C+c], "%v"), layer.cells[r*C+c])
+						fmt.Print(color(layer.cells[r*C+c], glyph(layer.cells[r*C+c])))
 					}
 				}
 			}
@@ -543,6 +507,20 @@ var COLOR = []string{
 	Red,
 }
 
+// pieces past 9 are drawn as letters, so that every cell stays 1 character wide
+const glyphs = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+func glyph(num int8) string {
+	if num < 0 || int(num) >= len(glyphs) {
+		return "#"
+	}
+	return glyphs[num : num+1]
+}
+
 func color(num int8, str string) string {
-	return COLOR[num] + str + Reset
+	if num < 0 {
+		return str
+	}
+	// pieces past the last colour start over
+	return COLOR[int(num)%len(COLOR)] + str + Reset
 }
@@ -0,0 +1,208 @@
+package lib2
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+/*
+
+	mcts = monte carlo tree search over placements and draws
+	decision node = a position where a known number has to be placed, its children are placements
+	chance node = the position right after a placement, its children are the next draws,
+				  sampled from the remaining deck so likely draws get visited more
+	rollout = the rest of the game (or RolloutDepth draws) played fast from a leaf
+	value = points scored from the root onwards, so values of different placements compare directly
+
+	an iteration walks down choosing placements by UCB1 and sampling draws, adds one
+	placement, plays a rollout from it and adds the points to every placement on the way
+
+*/
+
+type MCTSConfig struct {
+	Iterations   int           // 0 means no limit on iterations
+	Budget       time.Duration // 0 means no limit on time
+	Exploration  float64       // UCB1 constant, in points
+	RolloutDepth int           // 0 means play every remaining card
+	Rollout      string        // "greedy" or "random"
+}
+
+func DefaultMCTSConfig() MCTSConfig {
+	return MCTSConfig{Iterations: 2000, Exploration: 10, Rollout: "greedy"}
+}
+
+// visits and mean value of one placement at the root
+type MCTSStat struct {
+	Move   Move
+	Visits int
+	Mean   float64
+}
+
+type MCTSStrategy struct {
+	Config MCTSConfig
+	rng    *rand.Rand
+	// stats of the last search, best first
+	Stats []MCTSStat
+}
+
+func NewMCTSStrategy(cfg MCTSConfig, seed int64) *MCTSStrategy {
+	return &MCTSStrategy{Config: cfg, rng: rand.New(rand.NewSource(seed))}
+}
+
+func (s *MCTSStrategy) Name() string {
+	if s.Config.Budget > 0 {
+		return fmt.Sprintf("mcts:%v:%v", s.Config.Iterations, s.Config.Budget.Milliseconds())
+	}
+	return fmt.Sprintf("mcts:%v", s.Config.Iterations)
+}
+
+type mctsNode struct {
+	move   Move
+	visits int
+	total  float64
+	// chance children, indexed by the number drawn next
+	draws [][]*mctsNode
+}
+
+func (node *mctsNode) mean() float64 {
+	if node.visits == 0 {
+		return 0
+	}
+	return node.total / float64(node.visits)
+}
+
+func (s *MCTSStrategy) ChooseMove(board *Board, num int, remaining []int) (Move, error) {
+	board.fanout = []int{0}
+	moves := board.LegalMoves(num)
+	if len(moves) == 0 {
+		return Move{}, noValidMoves(num)
+	}
+	root := make([]*mctsNode, len(moves))
+	for i, m := range moves {
+		root[i] = &mctsNode{move: m}
+	}
+	if len(moves) > 1 {
+		s.search(board, root, remaining)
+	}
+	s.Stats = make([]MCTSStat, len(root))
+	for i, node := range root {
+		s.Stats[i] = MCTSStat{Move: node.move, Visits: node.visits, Mean: node.mean()}
+	}
+	// the most visited placement is the one the search trusts the most
+	sort.SliceStable(s.Stats, func(i, j int) bool {
+		if s.Stats[i].Visits != s.Stats[j].Visits {
+			return s.Stats[i].Visits > s.Stats[j].Visits
+		}
+		return s.Stats[i].Mean > s.Stats[j].Mean
+	})
+	return s.Stats[0].Move, nil
+}
+
+func (s *MCTSStrategy) search(board *Board, root []*mctsNode, remaining []int) {
+	start := time.Now()
+	iterations := s.Config.Iterations
+	if iterations == 0 && s.Config.Budget == 0 {
+		iterations = DefaultMCTSConfig().Iterations
+	}
+	left := make([]int, len(remaining))
+	for i := 0; iterations == 0 || i < iterations; i++ {
+		// check the clock every few iterations, it is slow compared to a rollout step
+		if s.Config.Budget > 0 && i%16 == 0 && time.Since(start) > s.Config.Budget {
+			break
+		}
+		copy(left, remaining)
+		s.iterate(board, copyFlat(board.flat), root, left)
+	}
+}
+
+// walks one path down from children, returns the points scored from here on
+func (s *MCTSStrategy) iterate(board *Board, flat *Layer, children []*mctsNode, remaining []int) float64 {
+	node := s.selectChild(children)
+	board.placeOnFlat(flat, node.move)
+	board.fanout[0]++
+	value := float64(board.score(node.move.Num, node.move.Level))
+	cards := deckCards(remaining)
+	if node.visits == 0 || len(cards) == 0 {
+		// leaf: play the rest of the game fast
+		value += float64(s.rollout(board, flat, cards))
+	} else {
+		next := cards[s.rng.Intn(len(cards))]
+		remaining[next]--
+		if node.draws == nil {
+			node.draws = make([][]*mctsNode, len(remaining))
+		}
+		if node.draws[next] == nil {
+			moves := board.legalMovesOn(flat, next)
+			node.draws[next] = make([]*mctsNode, len(moves))
+			for i, m := range moves {
+				node.draws[next][i] = &mctsNode{move: m}
+			}
+		}
+		if len(node.draws[next]) > 0 {
+			value += s.iterate(board, flat, node.draws[next], remaining)
+		} else {
+			// the drawn number doesn't fit anywhere, the game goes on without it
+			value += float64(s.rollout(board, flat, deckCards(remaining)))
+		}
+	}
+	node.visits++
+	node.total += value
+	return value
+}
+
+// UCB1, every child is tried once before any is tried twice
+func (s *MCTSStrategy) selectChild(children []*mctsNode) *mctsNode {
+	parentVisits := 0
+	for _, child := range children {
+		if child.visits == 0 {
+			return child
+		}
+		parentVisits += child.visits
+	}
+	logN := math.Log(float64(parentVisits))
+	var best *mctsNode
+	bestUCB := math.Inf(-1)
+	for _, child := range children {
+		ucb := child.mean() + s.Config.Exploration*math.Sqrt(logN/float64(child.visits))
+		if ucb > bestUCB {
+			bestUCB = ucb
+			best = child
+		}
+	}
+	return best
+}
+
+func (s *MCTSStrategy) rollout(board *Board, flat *Layer, cards []int) int {
+	s.rng.Shuffle(len(cards), func(a, b int) {
+		cards[a], cards[b] = cards[b], cards[a]
+	})
+	if s.Config.RolloutDepth > 0 && len(cards) > s.Config.RolloutDepth {
+		cards = cards[:s.Config.RolloutDepth]
+	}
+	if s.Config.Rollout == "random" {
+		total := 0
+		for _, num := range cards {
+			moves := board.legalMovesOn(flat, num)
+			board.fanout[0] += len(moves)
+			if len(moves) == 0 {
+				continue
+			}
+			m := moves[s.rng.Intn(len(moves))]
+			board.placeOnFlat(flat, m)
+			total += board.score(num, m.Level)
+		}
+		return total
+	}
+	return board.rollout(flat, cards)
+}
+
+func (s *MCTSStrategy) PrintStats(w io.Writer) {
+	for _, stat := range s.Stats {
+		m := stat.Move
+		fmt.Fprintf(w, "  %v: visits %v mean %.2f\n", placement(m), stat.Visits, stat.Mean)
+	}
+}
@@ -0,0 +1,61 @@
+package lib2
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestMCTSPicksObviousMove(t *testing.T) {
+	R, C := 4, 7
+	board := newBoardRC(R, C, 1)
+	board.putNumberAtLayer(0, 9, 0, 0, 1)
+	/*
+		setup:    best:
+		.999...   .988...
+		.999...   .988...
+		.99....   .88....
+		.99....   .88....
+	*/
+	cfg := DefaultMCTSConfig()
+	cfg.Iterations = 200
+	s := NewMCTSStrategy(cfg, 1)
+	remaining := board.Remaining()
+	remaining[8]--
+	move, err := s.ChooseMove(board, 8, remaining)
+	if err != nil {
+		t.Fatalf("err choosing move: %v", err)
+	}
+	if want := (Move{Num: 8, Row: 0, Col: 1, Level: 1}); move != want {
+		var buf bytes.Buffer
+		s.PrintStats(&buf)
+		t.Fatalf("want:%v != got:%v\n%v", want, move, buf.String())
+	}
+	visits := 0
+	for _, stat := range s.Stats {
+		visits += stat.Visits
+	}
+	if visits != cfg.Iterations {
+		t.Fatalf("root visits: want:%v != got:%v", cfg.Iterations, visits)
+	}
+	if s.Stats[0].Mean < 8 {
+		t.Fatalf("best mean should include the 8 points of the move: %v", s.Stats[0].Mean)
+	}
+}
+
+func TestMCTSTimeBudget(t *testing.T) {
+	board := NewBoard()
+	board.ApplyMove(Move{Num: 5})
+	cfg := MCTSConfig{Budget: 20 * time.Millisecond, Exploration: 10, Rollout: "random"}
+	s := NewMCTSStrategy(cfg, 1)
+	start := time.Now()
+	if _, err := board.Play(s, 7); err != nil {
+		t.Fatalf("err playing: %v", err)
+	}
+	if took := time.Since(start); took > time.Second {
+		t.Fatalf("search ignored its budget: %v", took)
+	}
+	if s.Stats[0].Visits == 0 {
+		t.Fatalf("search should have visited the best move")
+	}
+}
@@ -0,0 +1,175 @@
+package lib2
+
+import (
+	"fmt"
+	"slices"
+
+	"nmbr9/core"
+)
+
+/*
+
+	orientation = one way a shape can lie on the table, numbered 0-7
+				  code%4 = quarter turns clockwise
+				  code>=4 = flipped over, mirrored left to right before turning
+				  0 is the shape as drawn
+	orientations = which of them a game allows, the standard game plays shapes as drawn
+			fixed			0
+			rotate			0-3
+			flip			0 and 4
+			rotate+flip		0-7
+	look = what an orientation covers, symmetric shapes have the same look in several
+		   orientations, only the lowest code of every look is played so the searches
+		   don't try the same placement twice
+			0 with rotate+flip has 2 looks: 0 (upright) and 1 (lying down)
+
+*/
+
+type Orientations int
+
+const (
+	Fixed Orientations = iota
+	Rotate
+	Flip
+	RotateFlip
+)
+
+const OrientationNames = "fixed, rotate, flip, rotate+flip"
+
+var orientationNames = []string{"fixed", "rotate", "flip", "rotate+flip"}
+
+func OrientationsByName(name string) (Orientations, error) {
+	for o, n := range orientationNames {
+		if n == name {
+			return Orientations(o), nil
+		}
+	}
+	return Fixed, fmt.Errorf("unknown orientations: %v", name)
+}
+
+func (o Orientations) String() string {
+	if o < Fixed || o > RotateFlip {
+		return fmt.Sprintf("orientations(%d)", int(o))
+	}
+	return orientationNames[o]
+}
+
+// saved by name
+func (o Orientations) MarshalText() ([]byte, error) {
+	if o < Fixed || o > RotateFlip {
+		return nil, fmt.Errorf("unknown orientations: %d", int(o))
+	}
+	return []byte(o.String()), nil
+}
+
+func (o *Orientations) UnmarshalText(text []byte) error {
+	var err error
+	*o, err = OrientationsByName(string(text))
+	return err
+}
+
+// every code o allows, look-alikes included
+func (o Orientations) codes() []int {
+	switch o {
+	case Rotate:
+		return []int{0, 1, 2, 3}
+	case Flip:
+		return []int{0, 4}
+	case RotateFlip:
+		return []int{0, 1, 2, 3, 4, 5, 6, 7}
+	}
+	return []int{0}
+}
+
+// shape in orientation code
+func (shape *Shape) orient(code int) *core.Piece {
+	o := &core.Piece{Rows: shape.Rows, Cols: shape.Cols, Tiles: append([]int8{}, shape.tiles...)}
+	if code >= 4 {
+		for r := 0; r < o.Rows; r++ {
+			slices.Reverse(o.Tiles[r*o.Cols : (r+1)*o.Cols])
+		}
+	}
+	for t := 0; t < code%4; t++ {
+		// quarter turn clockwise: the left column becomes the top row
+		turned := make([]int8, len(o.Tiles))
+		for r := 0; r < o.Cols; r++ {
+			for c := 0; c < o.Rows; c++ {
+				turned[r*o.Rows+c] = o.Tiles[(o.Rows-1-c)*o.Cols+r]
+			}
+		}
+		o.Rows, o.Cols, o.Tiles = o.Cols, o.Rows, turned
+	}
+	return o
+}
+
+// works out every orientation of shape and which of them every set of
+// orientations plays
+func (shape *Shape) orientAll() {
+	for code := range shape.orients {
+		shape.orients[code] = shape.orient(code)
+	}
+	for o := Fixed; o <= RotateFlip; o++ {
+		shape.looks[o] = nil
+		for code := range shape.canon[o] {
+			shape.canon[o][code] = -1
+		}
+		for _, code := range o.codes() {
+			shape.canon[o][code] = code
+			for _, look := range shape.looks[o] {
+				if same(shape.orients[look], shape.orients[code]) {
+					shape.canon[o][code] = look
+					break
+				}
+			}
+			if shape.canon[o][code] == code {
+				shape.looks[o] = append(shape.looks[o], code)
+			}
+		}
+	}
+}
+
+func same(o, other *core.Piece) bool {
+	return o.Rows == other.Rows && o.Cols == other.Cols && slices.Equal(o.Tiles, other.Tiles)
+}
+
+// the orientations of num the board plays, one per look
+func (board *Board) orients(num int) []int {
+	return board.shape(num).looks[board.rules.Orientations]
+}
+
+func (board *Board) orient(num, o int) *core.Piece {
+	return board.shape(num).orients[o]
+}
+
+// the code num is played with for orientation o, error if the board doesn't allow o
+func (board *Board) canonical(num, o int) (int, error) {
+	if o >= 0 && o < 8 {
+		if code := board.shape(num).canon[board.rules.Orientations][o]; code >= 0 {
+			return code, nil
+		}
+	}
+	return 0, fmt.Errorf("num:%v can't be played in orientation:%v with %v", num, o, board.rules.Orientations)
+}
+
+func (board *Board) Orientations() Orientations {
+	return board.rules.Orientations
+}
+
+// plays the game with o, only before the first card since the record keeps one
+// set of orientations for the whole game
+func (board *Board) SetOrientations(o Orientations) error {
+	if o < Fixed || o > RotateFlip {
+		return fmt.Errorf("unknown orientations: %d", int(o))
+	}
+	if len(board.drawn) > 0 {
+		return fmt.Errorf("orientations can only change before the first card")
+	}
+	if o == Rotate || o == RotateFlip {
+		// lying down every shape needs its rows across the board
+		if err := board.rules.Shapes.fits(board.C, board.R); err != nil {
+			return fmt.Errorf("can't rotate: %v", err)
+		}
+	}
+	board.rules.Orientations = o
+	return nil
+}
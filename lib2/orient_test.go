@@ -0,0 +1,125 @@
+package lib2
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOrientTurnsAndFlips(t *testing.T) {
+	seven := StandardShapes(2).Shapes[7]
+	tests := []struct {
+		code  int
+		cells []string
+	}{
+		{0, []string{"###", ".#.", "##.", "#.."}},
+		{1, []string{"##.#", ".###", "...#"}},
+		{2, []string{"..#", ".##", ".#.", "###"}},
+		{4, []string{"###", ".#.", ".##", "..#"}},
+	}
+	for _, tt := range tests {
+		o := seven.orient(tt.code)
+		want := &Shape{Rows: len(tt.cells), Cols: len(tt.cells[0]), Cells: tt.cells}
+		if err := want.validate(7); err != nil {
+			t.Fatalf("code:%v: %v", tt.code, err)
+		}
+		if o.Rows != want.Rows || o.Cols != want.Cols || !reflect.DeepEqual(o.Tiles, want.tiles) {
+			t.Fatalf("code:%v: want:%v != got:%v", tt.code, want.tiles, o.Tiles)
+		}
+	}
+}
+
+func TestSymmetricShapesHaveFewerLooks(t *testing.T) {
+	set := StandardShapes(2)
+	tests := []struct {
+		num   int
+		o     Orientations
+		looks []int
+	}{
+		{0, Fixed, []int{0}},
+		{0, Flip, []int{0}},
+		{0, RotateFlip, []int{0, 1}},
+		{8, Flip, []int{0, 4}},
+		{8, RotateFlip, []int{0, 1, 4, 5}},
+		{7, RotateFlip, []int{0, 1, 2, 3, 4, 5, 6, 7}},
+	}
+	for _, tt := range tests {
+		if got := set.Shapes[tt.num].looks[tt.o]; !reflect.DeepEqual(got, tt.looks) {
+			t.Fatalf("num:%v %v looks: want:%v != got:%v", tt.num, tt.o, tt.looks, got)
+		}
+	}
+}
+
+func TestFlippedMoves(t *testing.T) {
+	board := NewBoard()
+	if err := board.SetOrientations(Flip); err != nil {
+		t.Fatalf("err setting orientations: %v", err)
+	}
+	if err := board.ApplyMove(Move{Num: 7, Orient: 4}); err != nil {
+		t.Fatalf("err applying move: %v", err)
+	}
+	if got := board.history[0].Orient; got != 4 {
+		t.Fatalf("first move orientation: want:4 != got:%v", got)
+	}
+	// 0 looks the same flipped, both codes play as 0
+	if err := board.ApplyMove(Move{Num: 0, Row: 4, Col: 7, Orient: 4}); err != nil {
+		t.Fatalf("err applying move: %v", err)
+	}
+	if got := board.history[1].Orient; got != 0 {
+		t.Fatalf("flipped 0 orientation: want:0 != got:%v", got)
+	}
+	if err := board.ApplyMove(Move{Num: 5, Row: 0, Col: 0, Orient: 1}); err == nil {
+		t.Fatalf("turning should not be allowed with flip")
+	}
+	flipped := 0
+	for _, m := range board.LegalMoves(9) {
+		if m.Orient == 4 {
+			flipped++
+		}
+	}
+	if flipped == 0 {
+		t.Fatalf("no flipped moves for 9")
+	}
+	if err := board.SetOrientations(Fixed); err == nil {
+		t.Fatalf("orientations should not change mid game")
+	}
+}
+
+func TestRotatedGameRecord(t *testing.T) {
+	board := NewBoard()
+	if err := board.SetOrientations(RotateFlip); err != nil {
+		t.Fatalf("err setting orientations: %v", err)
+	}
+	if err := board.ApplyMove(Move{Num: 7, Orient: 1}); err != nil {
+		t.Fatalf("err applying move: %v", err)
+	}
+	strategy := LookaheadStrategy{Steps: 1}
+	for _, num := range []int{1, 5, 2, 9} {
+		if _, err := board.Play(strategy, num); err != nil {
+			t.Fatalf("err playing %v: %v", num, err)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "game.json")
+	if err := board.SaveGame(path); err != nil {
+		t.Fatalf("err saving: %v", err)
+	}
+	rec, err := LoadGame(path)
+	if err != nil {
+		t.Fatalf("err loading: %v", err)
+	}
+	if rec.Orientations != RotateFlip {
+		t.Fatalf("orientations: want:%v != got:%v", RotateFlip, rec.Orientations)
+	}
+	replayed, err := rec.Replay()
+	if err != nil {
+		t.Fatalf("err replaying: %v", err)
+	}
+	if !reflect.DeepEqual(replayed.flat, board.flat) || replayed.Score() != board.Score() {
+		t.Fatalf("replayed board differs")
+	}
+	// the same record played without turning can't be replayed
+	rec.Orientations = Fixed
+	if _, err := rec.Replay(); err == nil {
+		t.Fatalf("turned moves replayed on a fixed board")
+	}
+}
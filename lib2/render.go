@@ -0,0 +1,98 @@
+package lib2
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+
+	iso = single picture of the whole stack, drawn back (row 0) to front
+		  every cell is 2 chars wide, every level is drawn 1 row up and 1 col right
+		  the top face of a stack shows the number placed there, covered levels show as walls
+
+			flat:		iso:
+			.011...		   8888
+			.011...		99▒8888
+			.11....		98888▒
+			.11....		▒8888
+							▒▒▒▒
+
+	notes:
+		* higher levels are also drawn bold so they stand out from the floor
+
+*/
+
+var Bold = "\033[1m"
+
+const isoWall = '▒'
+
+// renders the whole stack as one isometric picture using flat for the height
+// of every cell and layers for the number on top of it
+func (board *Board) RenderIso() string {
+	flat := board.flat
+	if len(board.layers) == 0 || flat.BB_TL_R > flat.BB_BR_R {
+		return "(empty)\n"
+	}
+	C := board.C
+	top := len(board.layers) - 1
+	tlR, tlC := flat.BB_TL_R, flat.BB_TL_C
+	brR, brC := flat.BB_BR_R, flat.BB_BR_C
+	H := brR - tlR + 1 + top
+	W := 2*(brC-tlC+1) + top
+	// every canvas position holds one visible char, already colored
+	canvas := make([][]string, H)
+	for y := range canvas {
+		canvas[y] = make([]string, W)
+	}
+	// painter's order: back rows first, lower levels first, so the front and
+	// the top of each stack are drawn last and cover whatever is behind them
+	for r := tlR; r <= brR; r++ {
+		for c := tlC; c <= brC; c++ {
+			height := int(flat.cells[r*C+c])
+			for l := 0; l <= height; l++ {
+				num := board.layers[l].cells[r*C+c]
+				if num == EMPTY {
+					// hole under a bridging tile, nothing to draw at this level
+					continue
+				}
+				y := (r - tlR) + top - l
+				x := 2*(c-tlC) + l
+				glyph := isoGlyph(num, l, l < height)
+				canvas[y][x] = glyph
+				canvas[y][x+1] = glyph
+			}
+		}
+	}
+	var sb strings.Builder
+	for _, row := range canvas {
+		// drop trailing empty space so the picture is as narrow as possible
+		end := len(row)
+		for end > 0 && row[end-1] == "" {
+			end--
+		}
+		for _, glyph := range row[:end] {
+			if glyph == "" {
+				glyph = " "
+			}
+			sb.WriteString(glyph)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+func isoGlyph(num int8, level int, wall bool) string {
+	str := glyph(num)
+	if wall {
+		str = string(isoWall)
+	}
+	if level > 0 {
+		str = Bold + str
+	}
+	return color(num, str)
+}
+
+func (board *Board) PrintIso() {
+	fmt.Print(board.RenderIso())
+}
@@ -0,0 +1,74 @@
+package lib2
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var ansi = regexp.MustCompile("\033\\[[0-9;]*m")
+
+func stripANSI(str string) string {
+	return ansi.ReplaceAllString(str, "")
+}
+
+func TestRenderIso(t *testing.T) {
+	R, C := 4, 7
+	board := newBoardRC(R, C, 1)
+	board.putNumberAtLayer(0, 9, 0, 0, 1)
+	board.putNumberAtLayer(1, 8, 0, 0, 1)
+	/*
+		flat:
+		.011...
+		.011...
+		.11....
+		.11....
+	*/
+	want := strings.Join([]string{
+		"   8888",
+		"99▒8888",
+		"98888▒",
+		"▒8888",
+		"▒▒▒▒",
+		"",
+	}, "\n")
+	got := board.RenderIso()
+	if stripANSI(got) != want {
+		fmt.Println("want:")
+		fmt.Print(want)
+		fmt.Println("got:")
+		fmt.Print(got)
+		t.Fatalf("want not equal to got")
+	}
+	if !strings.Contains(got, Bold) {
+		t.Fatalf("level 1 should be drawn bold")
+	}
+}
+
+func TestRenderIsoEmpty(t *testing.T) {
+	board := newBoardRC(4, 7, 1)
+	if got, want := board.RenderIso(), "(empty)\n"; got != want {
+		t.Fatalf("want:%q != got:%q", want, got)
+	}
+}
+
+func TestRenderIsoFitsTerminal(t *testing.T) {
+	input := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	board := NewBoard()
+	steps := 1
+	for _, n := range input {
+		if err, _ := board.ApplyBestMove(n, steps); err != nil {
+			t.Fatalf("err applying best move: %v", err)
+		}
+	}
+	iso := stripANSI(board.RenderIso())
+	if height := strings.Count(iso, "\n"); height == 0 || height > board.R+len(board.layers) {
+		t.Fatalf("picture %v lines high, want 1 to %v:\n%v", height, board.R+len(board.layers), iso)
+	}
+	for _, line := range strings.Split(iso, "\n") {
+		if width := len([]rune(line)); width > 2*board.C+len(board.layers) {
+			t.Fatalf("line too wide: %v > %v", width, 2*board.C+len(board.layers))
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package lib2
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+
+	rules = every switch that decides what a placement may be and what it scores:
+			board limits, pieces, turning and flipping, the support and adjacency
+			rules, the deck and the scoring
+			a board carries its own rules so boards with different rules can be
+			searched side by side
+	support = what a piece above the bottom has to lie on
+			level		every tile on the same level, the standard rule
+			bottom		nothing is stacked, every piece goes on level 0
+	presets
+			official	12x12, the numbers 0-9 as drawn, 2 of each, standard scoring
+			beginner	like official with 1 of each, pieces can be turned and level 0
+						scores too, a short game where every piece counts
+			house		like official with pieces turned and flipped, a piece on a level
+						that has pieces already has to touch one of them
+
+*/
+
+const RulesNames = "official, beginner, house"
+
+type Rules struct {
+	Name         string
+	R            int
+	C            int
+	Shapes       *ShapeSet // nil for the numbers 0-9
+	Orientations Orientations
+	Support      Support
+	Adjacency    Adjacency
+	Deck         Deck     // nil for the copies of every shape
+	Scoring      *Scoring // nil for the standard scoring
+}
+
+func OfficialRules() *Rules {
+	return &Rules{Name: "official", R: 12, C: 12}
+}
+
+func RulesByName(name string) (*Rules, error) {
+	rules := OfficialRules()
+	rules.Name = name
+	switch name {
+	case "official":
+	case "beginner":
+		rules.Orientations = Rotate
+		rules.Deck = UniformDeck(len(NUMBER), 1)
+		rules.Scoring = &Scoring{Name: "level0", Offset: 1}
+	case "house":
+		rules.Orientations = RotateFlip
+		rules.Adjacency = TouchEveryLevel
+	default:
+		return nil, fmt.Errorf("unknown rules: %v", name)
+	}
+	return rules, nil
+}
+
+// an empty board played by rules
+func NewBoardWithRules(rules *Rules) (*Board, error) {
+	if rules.R < 1 || rules.C < 1 {
+		return nil, fmt.Errorf("rules:%v board %vx%v is too small", rules.Name, rules.R, rules.C)
+	}
+	board := newBoardRC(rules.R, rules.C, 2)
+	if rules.Shapes != nil {
+		var err error
+		if board, err = NewBoardWithShapes(rules.R, rules.C, rules.Shapes); err != nil {
+			return nil, err
+		}
+	} else if err := board.rules.Shapes.fits(rules.R, rules.C); err != nil {
+		return nil, err
+	}
+	board.rules.Name = rules.Name
+	if rules.Deck != nil {
+		if err := board.SetDeck(rules.Deck); err != nil {
+			return nil, err
+		}
+	}
+	if rules.Scoring != nil {
+		board.SetScoring(rules.Scoring)
+	}
+	if err := board.SetOrientations(rules.Orientations); err != nil {
+		return nil, err
+	}
+	if err := board.SetSupport(rules.Support); err != nil {
+		return nil, err
+	}
+	if err := board.SetAdjacency(rules.Adjacency); err != nil {
+		return nil, err
+	}
+	return board, nil
+}
+
+// the rules the board is played by
+func (board *Board) Rules() *Rules {
+	rules := board.rules
+	rules.R, rules.C = board.R, board.C
+	rules.Deck = board.Deck()
+	return &rules
+}
+
+func (rules *Rules) String() string {
+	parts := []string{rules.Name}
+	if rules.Shapes != nil {
+		parts = append(parts, "shapes "+rules.Shapes.Name)
+	}
+	parts = append(parts, fmt.Sprintf("%vx%v", rules.R, rules.C), rules.Orientations.String(),
+		"support "+rules.Support.String(), "adjacency "+rules.Adjacency.String())
+	if rules.Deck != nil {
+		parts = append(parts, "deck "+rules.Deck.String())
+	}
+	if rules.Scoring != nil {
+		parts = append(parts, "scoring "+rules.Scoring.Name)
+	}
+	return strings.Join(parts, ", ")
+}
+
+type Support int
+
+const (
+	SupportLevel Support = iota
+	SupportBottom
+)
+
+const SupportNames = "level, bottom"
+
+var supportNames = []string{"level", "bottom"}
+
+func SupportByName(name string) (Support, error) {
+	for s, n := range supportNames {
+		if n == name {
+			return Support(s), nil
+		}
+	}
+	return SupportLevel, fmt.Errorf("unknown support: %v", name)
+}
+
+func (s Support) String() string {
+	if s < SupportLevel || s > SupportBottom {
+		return fmt.Sprintf("support(%d)", int(s))
+	}
+	return supportNames[s]
+}
+
+// saved by name
+func (s Support) MarshalText() ([]byte, error) {
+	if s < SupportLevel || s > SupportBottom {
+		return nil, fmt.Errorf("unknown support: %d", int(s))
+	}
+	return []byte(s.String()), nil
+}
+
+func (s *Support) UnmarshalText(text []byte) error {
+	var err error
+	*s, err = SupportByName(string(text))
+	return err
+}
+
+func (board *Board) Support() Support {
+	return board.rules.Support
+}
+
+// plays the game with s, only before the first card like the orientations
+func (board *Board) SetSupport(s Support) error {
+	if s < SupportLevel || s > SupportBottom {
+		return fmt.Errorf("unknown support: %d", int(s))
+	}
+	if len(board.drawn) > 0 {
+		return fmt.Errorf("support can only change before the first card")
+	}
+	board.rules.Support = s
+	return nil
+}
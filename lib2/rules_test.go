@@ -0,0 +1,95 @@
+package lib2
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRulesPresets(t *testing.T) {
+	tests := []struct {
+		name   string
+		cards  int
+		orient Orientations
+		touch  Adjacency
+		score  int // 9 placed on level 0
+	}{
+		{"official", 20, Fixed, TouchBottom, 0},
+		{"beginner", 10, Rotate, TouchBottom, 9},
+		{"house", 20, RotateFlip, TouchEveryLevel, 0},
+	}
+	for _, tt := range tests {
+		rules, err := RulesByName(tt.name)
+		if err != nil {
+			t.Fatalf("err picking rules: %v", err)
+		}
+		board, err := NewBoardWithRules(rules)
+		if err != nil {
+			t.Fatalf("err making %v board: %v", tt.name, err)
+		}
+		got := board.Rules()
+		if got.Name != tt.name || got.Deck.Total() != tt.cards || got.Orientations != tt.orient || got.Adjacency != tt.touch {
+			t.Fatalf("%v rules: %v", tt.name, got)
+		}
+		if err := board.ApplyMove(Move{Num: 9}); err != nil {
+			t.Fatalf("err applying move: %v", err)
+		}
+		if board.Score() != tt.score {
+			t.Fatalf("%v score: want:%v != got:%v", tt.name, tt.score, board.Score())
+		}
+	}
+	if _, err := RulesByName("tournament"); err == nil {
+		t.Fatalf("unknown rules should be an error")
+	}
+}
+
+func TestRulesSideBySide(t *testing.T) {
+	official, _ := NewBoardWithRules(OfficialRules())
+	house, _ := NewBoardWithRules(&Rules{Name: "house", R: 12, C: 12, Orientations: RotateFlip})
+	for _, board := range []*Board{official, house} {
+		if err := board.ApplyMove(Move{Num: 7}); err != nil {
+			t.Fatalf("err applying move: %v", err)
+		}
+	}
+	// the same position, turning and flipping gives the house board more choices
+	if o, h := len(official.LegalMoves(2)), len(house.LegalMoves(2)); h <= o {
+		t.Fatalf("house moves:%v should be more than official moves:%v", h, o)
+	}
+	if official.Orientations() != Fixed {
+		t.Fatalf("official board picked up the house orientations")
+	}
+}
+
+func TestSupportBottom(t *testing.T) {
+	board, err := NewBoardWithRules(&Rules{Name: "flat", R: 12, C: 12, Support: SupportBottom})
+	if err != nil {
+		t.Fatalf("err making board: %v", err)
+	}
+	if err := board.ApplyMove(Move{Num: 9}); err != nil {
+		t.Fatalf("err applying move: %v", err)
+	}
+	first := board.history[0]
+	// 8 fits on top of the 9 with the standard support rule
+	if err := board.ApplyMove(Move{Num: 8, Row: first.Row, Col: first.Col}); err == nil {
+		t.Fatalf("stacking should not be allowed")
+	}
+	for _, m := range board.LegalMoves(8) {
+		if m.Level != 0 {
+			t.Fatalf("legal move above the bottom: %v", m)
+		}
+	}
+	path := filepath.Join(t.TempDir(), "game.json")
+	if err := board.SaveGame(path); err != nil {
+		t.Fatalf("err saving: %v", err)
+	}
+	rec, err := LoadGame(path)
+	if err != nil {
+		t.Fatalf("err loading: %v", err)
+	}
+	replayed, err := rec.Replay()
+	if err != nil {
+		t.Fatalf("err replaying: %v", err)
+	}
+	if rules := replayed.Rules(); rules.Name != "flat" || rules.Support != SupportBottom {
+		t.Fatalf("replayed rules: %v", rules)
+	}
+}
@@ -0,0 +1,114 @@
+package lib2
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+
+	scoring = how placements turn into points, every search, the final score and the
+			  analysis all go through the board's scoring
+	multiplier = what a piece's value is multiplied by at each level, the standard rule
+				 is the level itself so level 0 makes nothing
+	terms = points added on top of the placements, negative for a penalty
+			area: per cell of the table covered by the bottom level
+			unused: per drawn card that fit nowhere
+
+	scorings are picked by spec, a base rule and any number of '+' separated terms
+		standard				value * level
+		level0					value * (level+1), level 0 counts too
+		table:m0,m1,...			value * m[level], the last multiplier is used for every level above
+		standard+area:-1+unused:-5
+
+*/
+
+const ScoringNames = "standard, level0, table:m0,m1,... with optional +area:points and +unused:points terms"
+
+type Scoring struct {
+	Name        string
+	Offset      int   // added to the level, 1 makes level 0 count
+	Multipliers []int // replaces level+Offset when set
+	Area        int   // points per covered cell of the bottom level
+	Unused      int   // points per drawn card that fit nowhere
+}
+
+func StandardScoring() *Scoring {
+	return &Scoring{Name: "standard"}
+}
+
+func ScoringByName(spec string) (*Scoring, error) {
+	parts := strings.Split(spec, "+")
+	s := &Scoring{Name: spec}
+	base, args, _ := strings.Cut(parts[0], ":")
+	switch base {
+	case "standard":
+	case "level0":
+		s.Offset = 1
+	case "table":
+		for _, arg := range strings.Split(args, ",") {
+			m, err := strconv.Atoi(arg)
+			if err != nil {
+				return nil, fmt.Errorf("scoring:%v has bad multiplier:%v", spec, arg)
+			}
+			s.Multipliers = append(s.Multipliers, m)
+		}
+	default:
+		return nil, fmt.Errorf("unknown scoring: %v", spec)
+	}
+	if base != "table" && args != "" {
+		return nil, fmt.Errorf("scoring:%v takes no arguments", spec)
+	}
+	for _, part := range parts[1:] {
+		term, arg, _ := strings.Cut(part, ":")
+		points, err := strconv.Atoi(arg)
+		if err != nil {
+			return nil, fmt.Errorf("scoring:%v has bad points:%v", spec, arg)
+		}
+		switch term {
+		case "area":
+			s.Area = points
+		case "unused":
+			s.Unused = points
+		default:
+			return nil, fmt.Errorf("scoring:%v has unknown term:%v", spec, term)
+		}
+	}
+	return s, nil
+}
+
+func (s *Scoring) multiplier(level int8) int {
+	if len(s.Multipliers) > 0 {
+		return s.Multipliers[min(int(level), len(s.Multipliers)-1)]
+	}
+	return int(level) + s.Offset
+}
+
+// from now on placements are scored with s
+func (board *Board) SetScoring(s *Scoring) {
+	board.rules.Scoring = s
+}
+
+func (board *Board) Scoring() *Scoring {
+	return board.rules.Scoring
+}
+
+// points num makes placed at level
+func (board *Board) score(num int, level int8) int {
+	shape := board.shape(num)
+	points := shape.Value * board.rules.Scoring.multiplier(level)
+	if level == 0 {
+		points += board.rules.Scoring.Area * shape.size
+	}
+	return points
+}
+
+// most points num can make at level or any level below it, or by fitting nowhere
+func (board *Board) bestScore(num int, level int8) int {
+	best := board.rules.Scoring.Unused
+	for l := int8(0); l <= level; l++ {
+		best = max(best, board.score(num, l))
+	}
+	return best
+}
@@ -0,0 +1,82 @@
+package lib2
+
+import (
+	"testing"
+)
+
+func TestScoringByName(t *testing.T) {
+	tests := []struct {
+		spec  string
+		level []int // points of a 5 at levels 0, 1, 2, 3
+		area  int
+	}{
+		{"standard", []int{0, 5, 10, 15}, 0},
+		{"level0", []int{5, 10, 15, 20}, 0},
+		{"table:0,1,3", []int{0, 5, 15, 15}, 0},
+		{"standard+area:-1+unused:-3", []int{0, 5, 10, 15}, -1},
+	}
+	for _, tt := range tests {
+		s, err := ScoringByName(tt.spec)
+		if err != nil {
+			t.Fatalf("%v: err: %v", tt.spec, err)
+		}
+		board := NewBoard()
+		board.SetScoring(s)
+		for level, want := range tt.level {
+			if level == 0 {
+				// the 5 covers 10 cells of the table
+				want += tt.area * 10
+			}
+			if got := board.score(5, int8(level)); got != want {
+				t.Fatalf("%v: level %v: want:%v != got:%v", tt.spec, level, want, got)
+			}
+		}
+	}
+	for _, spec := range []string{"", "bogus", "table:1,x", "level0:2", "standard+area", "standard+holes:1"} {
+		if _, err := ScoringByName(spec); err == nil {
+			t.Fatalf("%v: want error", spec)
+		}
+	}
+}
+
+func TestScoreCountsUnusedCards(t *testing.T) {
+	s, _ := ScoringByName("level0+unused:-4")
+	// the 9 has nowhere to go next to the 1 on a board this small
+	board := newBoardRC(4, 4, 2)
+	board.SetScoring(s)
+	board.ApplyMove(Move{Num: 1})
+	if want := 1; board.Score() != want {
+		t.Fatalf("score: want:%v != got:%v", want, board.Score())
+	}
+	if err := board.Discard(9); err != nil {
+		t.Fatalf("err discarding: %v", err)
+	}
+	if want := 1 - 4; board.Score() != want {
+		t.Fatalf("score: want:%v != got:%v", want, board.Score())
+	}
+	rec := board.Record()
+	replayed, err := rec.Replay()
+	if err != nil {
+		t.Fatalf("err replaying: %v", err)
+	}
+	if replayed.Scoring().Name != s.Name || replayed.Score() != board.Score() {
+		t.Fatalf("replay: scoring %v score %v", replayed.Scoring().Name, replayed.Score())
+	}
+}
+
+func TestSolveIsOptimalUnderScorings(t *testing.T) {
+	// multipliers that drop at level 2 and a penalty for spreading out
+	for _, spec := range []string{"table:0,3,1,4", "level0+area:-1"} {
+		s, _ := ScoringByName(spec)
+		board := newBoardRC(6, 8, 1)
+		board.SetScoring(s)
+		cards := []int{9, 8, 7, 1}
+		sol, err := board.Solve(cards, SolveConfig{})
+		if err != nil {
+			t.Fatalf("%v: err solving: %v", spec, err)
+		}
+		if want := bruteForce(board, board.flat, cards); sol.Value != want {
+			t.Fatalf("%v: value: want:%v != got:%v", spec, want, sol.Value)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package lib2
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"nmbr9/core"
+)
+
+/*
+
+	shape = one kind of piece: a name, the points it makes per level, how many copies of it
+			are in the deck and its tiles drawn as rows of '#' (tile) and '.' (empty)
+	shape set = every piece a game is played with, the index of a shape is its number,
+				the standard set is NUMBER: 0-9 drawn 4x3, worth their number, 2 copies each
+
+	shapes file, text:
+		// comments and blank lines are skipped
+		piece 7 3x3 value 7 copies 2
+		###
+		.#.
+		#..
+
+	shapes file, json (any file ending in .json):
+		{"Name": "house", "Shapes": [{"Name": "7", "Rows": 3, "Cols": 3, "Value": 7, "Copies": 2,
+			"Cells": ["###", ".#.", "#.."]}]}
+
+	a shape must have at least one tile, every tile inside its declared rows and cols, and
+	all of its tiles connected through their sides
+
+*/
+
+const maxShapes = math.MaxInt8
+
+type Shape struct {
+	Name   string
+	Rows   int
+	Cols   int
+	Value  int // points per level
+	Copies int // copies in the deck
+	Cells  []string
+
+	tiles   []int8 // Rows*Cols, EMPTY where there is no tile
+	size    int    // how many tiles
+	orients [8]*core.Piece
+	looks   [4][]int  // codes played with every set of orientations
+	canon   [4][8]int // code played for every code, -1 if not allowed
+}
+
+type ShapeSet struct {
+	Name   string
+	Shapes []*Shape
+}
+
+// the numbers 0-9 of NUMBER, copies of each
+func StandardShapes(copies int) *ShapeSet {
+	set := &ShapeSet{Name: "standard"}
+	for num, raster := range NUMBER {
+		shape := &Shape{Name: strconv.Itoa(num), Rows: 4, Cols: 3, Value: num, Copies: copies}
+		for r := 0; r < shape.Rows; r++ {
+			row := make([]byte, shape.Cols)
+			for c := range row {
+				row[c] = '.'
+				if raster[r*shape.Cols+c] != EMPTY {
+					row[c] = '#'
+				}
+			}
+			shape.Cells = append(shape.Cells, string(row))
+		}
+		set.Shapes = append(set.Shapes, shape)
+	}
+	if err := set.validate(); err != nil {
+		panic(err)
+	}
+	return set
+}
+
+// reads a shapes file, json when the name ends in .json and text otherwise
+func LoadShapes(path string) (*ShapeSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	set := &ShapeSet{Name: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))}
+	if filepath.Ext(path) == ".json" {
+		if err := json.Unmarshal(data, set); err != nil {
+			return nil, fmt.Errorf("error reading shapes:%v: %v", path, err)
+		}
+	} else if set.Shapes, err = parseShapes(string(data)); err != nil {
+		return nil, fmt.Errorf("error reading shapes:%v: %v", path, err)
+	}
+	if err := set.validate(); err != nil {
+		return nil, fmt.Errorf("error reading shapes:%v: %v", path, err)
+	}
+	return set, nil
+}
+
+func (set *ShapeSet) Save(path string) error {
+	data, err := json.MarshalIndent(set, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func parseShapes(text string) ([]*Shape, error) {
+	shapes := []*Shape{}
+	var shape *Shape
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		switch {
+		case len(fields) == 0 || strings.HasPrefix(fields[0], "//"):
+			// blank line or comment
+		case fields[0] == "piece":
+			if len(fields) != 7 || fields[3] != "value" || fields[5] != "copies" {
+				return nil, fmt.Errorf("line %v: want: piece <name> <rows>x<cols> value <points> copies <n>", line)
+			}
+			shape = &Shape{Name: fields[1]}
+			_, err := fmt.Sscanf(fields[2], "%dx%d", &shape.Rows, &shape.Cols)
+			if err == nil {
+				shape.Value, err = strconv.Atoi(fields[4])
+			}
+			if err == nil {
+				shape.Copies, err = strconv.Atoi(fields[6])
+			}
+			if err != nil {
+				return nil, fmt.Errorf("line %v: %v", line, err)
+			}
+			shapes = append(shapes, shape)
+		case shape != nil && len(fields) == 1:
+			shape.Cells = append(shape.Cells, fields[0])
+		default:
+			return nil, fmt.Errorf("line %v: %q is not part of a piece", line, scanner.Text())
+		}
+	}
+	return shapes, nil
+}
+
+// checks every shape and works out its tiles
+func (set *ShapeSet) validate() error {
+	if len(set.Shapes) == 0 {
+		return fmt.Errorf("no shapes")
+	}
+	if len(set.Shapes) > maxShapes {
+		return fmt.Errorf("%v shapes, at most %v are supported", len(set.Shapes), maxShapes)
+	}
+	names := map[string]bool{}
+	for num, shape := range set.Shapes {
+		if shape == nil {
+			return fmt.Errorf("shape %v is empty", num)
+		}
+		if names[shape.Name] {
+			return fmt.Errorf("two shapes are called:%v", shape.Name)
+		}
+		names[shape.Name] = true
+		if err := shape.validate(int8(num)); err != nil {
+			return fmt.Errorf("shape:%v: %v", shape.Name, err)
+		}
+	}
+	return nil
+}
+
+func (shape *Shape) validate(num int8) error {
+	if shape.Rows < 1 || shape.Cols < 1 {
+		return fmt.Errorf("bounds %vx%v are too small", shape.Rows, shape.Cols)
+	}
+	if shape.Copies < 0 {
+		return fmt.Errorf("copies:%v can't be negative", shape.Copies)
+	}
+	if len(shape.Cells) != shape.Rows {
+		return fmt.Errorf("%v rows drawn, %v declared", len(shape.Cells), shape.Rows)
+	}
+	shape.tiles = make([]int8, shape.Rows*shape.Cols)
+	shape.size = 0
+	first := -1
+	for r, row := range shape.Cells {
+		if len(row) > shape.Cols {
+			return fmt.Errorf("row %v is %v wide, %v cols declared", r+1, len(row), shape.Cols)
+		}
+		for c := 0; c < shape.Cols; c++ {
+			shape.tiles[r*shape.Cols+c] = EMPTY
+			if c >= len(row) {
+				continue
+			}
+			switch row[c] {
+			case '#':
+				shape.tiles[r*shape.Cols+c] = num
+				shape.size++
+				if first < 0 {
+					first = r*shape.Cols + c
+				}
+			case '.':
+			default:
+				return fmt.Errorf("row %v has %q, only '#' and '.' are allowed", r+1, row[c])
+			}
+		}
+	}
+	if shape.size == 0 {
+		return fmt.Errorf("no tiles")
+	}
+	// flood fill from the first tile, every tile has to be reached
+	reached := map[int]bool{first: true}
+	stack := []int{first}
+	for len(stack) > 0 {
+		i := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		r, c := i/shape.Cols, i%shape.Cols
+		for _, d := range [4][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+			nr, nc := r+d[0], c+d[1]
+			if nr < 0 || nr >= shape.Rows || nc < 0 || nc >= shape.Cols {
+				continue
+			}
+			j := nr*shape.Cols + nc
+			if !reached[j] && shape.tiles[j] != EMPTY {
+				reached[j] = true
+				stack = append(stack, j)
+			}
+		}
+	}
+	if len(reached) != shape.size {
+		return fmt.Errorf("tiles are not connected")
+	}
+	shape.orientAll()
+	return nil
+}
+
+// the shapes all fit on an R x C board
+func (set *ShapeSet) fits(R, C int) error {
+	for _, shape := range set.Shapes {
+		if shape.Rows > R || shape.Cols > C {
+			return fmt.Errorf("shape:%v is %vx%v and doesn't fit on a %vx%v board", shape.Name, shape.Rows, shape.Cols, R, C)
+		}
+	}
+	return nil
+}
+
+// smallest number of tiles of any shape
+func (set *ShapeSet) minSize() int {
+	least := math.MaxInt
+	for _, shape := range set.Shapes {
+		least = min(least, shape.size)
+	}
+	return least
+}
@@ -0,0 +1,127 @@
+package lib2
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const houseShapes = `// two pieces
+piece I 4x1 value 1 copies 3
+#
+#
+#
+#
+
+piece T 2x3 value 4 copies 1
+###
+.#.
+`
+
+func writeShapes(t *testing.T, name, text string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		t.Fatalf("err writing shapes: %v", err)
+	}
+	return path
+}
+
+func TestStandardShapesMatchNumber(t *testing.T) {
+	set := StandardShapes(2)
+	for num, shape := range set.Shapes {
+		if !reflect.DeepEqual(shape.tiles, NUMBER[num]) {
+			t.Fatalf("num:%v tiles: want:%v != got:%v", num, NUMBER[num], shape.tiles)
+		}
+		if shape.Value != num || shape.Copies != 2 {
+			t.Fatalf("num:%v value:%v copies:%v", num, shape.Value, shape.Copies)
+		}
+	}
+	if set.minSize() != 5 {
+		t.Fatalf("smallest number: want:5 != got:%v", set.minSize())
+	}
+}
+
+func TestLoadShapes(t *testing.T) {
+	set, err := LoadShapes(writeShapes(t, "house.txt", houseShapes))
+	if err != nil {
+		t.Fatalf("err loading shapes: %v", err)
+	}
+	if set.Name != "house" || len(set.Shapes) != 2 {
+		t.Fatalf("set: %+v", set)
+	}
+	if tee := set.Shapes[1]; tee.Rows != 2 || tee.Cols != 3 || tee.Value != 4 || tee.Copies != 1 || tee.size != 4 {
+		t.Fatalf("T: %+v", tee)
+	}
+	// the json written by Save loads to the same set
+	path := filepath.Join(t.TempDir(), "house.json")
+	if err := set.Save(path); err != nil {
+		t.Fatalf("err saving shapes: %v", err)
+	}
+	loaded, err := LoadShapes(path)
+	if err != nil {
+		t.Fatalf("err loading json shapes: %v", err)
+	}
+	if !reflect.DeepEqual(set, loaded) {
+		t.Fatalf("json: want:%+v != got:%+v", set, loaded)
+	}
+}
+
+func TestLoadShapesValidates(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		err  string
+	}{
+		{"disconnected", "piece a 2x2 value 1 copies 1\n#.\n.#\n", "not connected"},
+		{"too wide", "piece a 1x2 value 1 copies 1\n###\n", "wide"},
+		{"too many rows", "piece a 1x2 value 1 copies 1\n##\n##\n", "rows drawn"},
+		{"no tiles", "piece a 1x2 value 1 copies 1\n..\n", "no tiles"},
+		{"bad cell", "piece a 1x2 value 1 copies 1\n#x\n", "only"},
+		{"bad header", "piece a 1x2 value 1\n##\n", "want"},
+		{"same name", "piece a 1x1 value 1 copies 1\n#\n\npiece a 1x1 value 1 copies 1\n#\n", "two shapes"},
+		{"empty", "// nothing\n", "no shapes"},
+	}
+	for _, tt := range tests {
+		_, err := LoadShapes(writeShapes(t, tt.name+".txt", tt.text))
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Fatalf("%v: want error with:%q != got:%v", tt.name, tt.err, err)
+		}
+	}
+}
+
+func TestBoardWithShapes(t *testing.T) {
+	set, _ := LoadShapes(writeShapes(t, "house.txt", houseShapes))
+	if _, err := NewBoardWithShapes(3, 3, set); err == nil {
+		t.Fatalf("a 4 row piece shouldn't fit on a 3x3 board")
+	}
+	board, err := NewBoardWithShapes(8, 8, set)
+	if err != nil {
+		t.Fatalf("err making board: %v", err)
+	}
+	// three I's side by side and the T on top of them, 4 points a level
+	for _, m := range []Move{{Num: 0}, {Num: 0, Row: 2, Col: 2}, {Num: 0, Row: 2, Col: 4}, {Num: 1, Row: 2, Col: 2}} {
+		if err := board.ApplyMove(m); err != nil {
+			t.Fatalf("err applying %+v: %v", m, err)
+		}
+	}
+	if want := 4; board.Score() != want {
+		board.PrintOverlays(true)
+		t.Fatalf("score: want:%v != got:%v", want, board.Score())
+	}
+	if _, err := board.Play(GreedyStrategy{}, 1); err == nil {
+		t.Fatalf("the only T has been placed already")
+	}
+	if got := board.Remaining(); !reflect.DeepEqual(got, []int{0, 0}) {
+		t.Fatalf("remaining: want:[0 0] != got:%v", got)
+	}
+	rec := board.Record()
+	replayed, err := rec.Replay()
+	if err != nil {
+		t.Fatalf("err replaying: %v", err)
+	}
+	if replayed.Score() != board.Score() || replayed.Shapes() != set {
+		t.Fatalf("replayed game differs")
+	}
+}
@@ -0,0 +1,294 @@
+package lib2
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+
+	simulation = many complete games played by the engine, one shuffled deck per game
+	deck = every number 0-9 repeated seenLimit times, 20 cards for the default board,
+		   or any other Deck
+	game i is played with seed+i so any single game can be replayed on its own
+
+*/
+
+type SimConfig struct {
+	Games     int
+	Seed      int64
+	Strategy  string
+	R         int
+	C         int
+	SeenLimit int
+	Rules     *Rules   // nil for the official rules on R x C with SeenLimit copies of every number
+	Weights   *Weights // evaluation at the search horizon, nil to skip
+	Endgame   int      // cards left below which searches are exact, 0 to skip
+}
+
+func DefaultSimConfig() SimConfig {
+	return SimConfig{Games: 100, Seed: 1, Strategy: "lookahead:2", R: 12, C: 12, SeenLimit: 2}
+}
+
+type GameResult struct {
+	Game      int
+	Seed      int64
+	Cards     []int
+	Score     int
+	Skipped   int // cards that had no valid placement
+	MoveTimes []time.Duration
+	Nodes     []int
+}
+
+func (res GameResult) TotalTime() time.Duration {
+	var total time.Duration
+	for _, d := range res.MoveTimes {
+		total += d
+	}
+	return total
+}
+
+func (res GameResult) TotalNodes() int {
+	total := 0
+	for _, n := range res.Nodes {
+		total += n
+	}
+	return total
+}
+
+// every number in 0-9 repeated seenLimit times, shuffled by rng
+func ShuffledDeck(rng *rand.Rand, seenLimit int) []int {
+	return UniformDeck(len(NUMBER), seenLimit).Shuffled(rng)
+}
+
+func Simulate(cfg SimConfig) ([]GameResult, error) {
+	results := make([]GameResult, cfg.Games)
+	for g := 0; g < cfg.Games; g++ {
+		seed := cfg.Seed + int64(g)
+		strategy, err := StrategyByName(cfg.Strategy, seed)
+		if err != nil {
+			return nil, err
+		}
+		board, err := NewBoardWithRules(cfg.rules())
+		if err != nil {
+			return nil, err
+		}
+		cards := board.rules.Deck.Shuffled(rand.New(rand.NewSource(seed)))
+		board.SetWeights(cfg.Weights)
+		board.SetEndgame(cfg.Endgame)
+		res := PlayGame(board, cards, strategy)
+		res.Game = g
+		res.Seed = seed
+		results[g] = res
+	}
+	return results, nil
+}
+
+func (cfg SimConfig) rules() *Rules {
+	if cfg.Rules != nil {
+		return cfg.Rules
+	}
+	rules := OfficialRules()
+	rules.R, rules.C = cfg.R, cfg.C
+	rules.Deck = UniformDeck(len(NUMBER), cfg.SeenLimit)
+	return rules
+}
+
+// plays every card in order on board and records how strategy did
+func PlayGame(board *Board, cards []int, strategy Strategy) GameResult {
+	res := GameResult{
+		Cards:     cards,
+		MoveTimes: make([]time.Duration, 0, len(cards)),
+		Nodes:     make([]int, 0, len(cards)),
+	}
+	for _, num := range cards {
+		start := time.Now()
+		_, err := board.Play(strategy, num)
+		res.MoveTimes = append(res.MoveTimes, time.Since(start))
+		res.Nodes = append(res.Nodes, board.Nodes())
+		if err != nil {
+			res.Skipped++
+		}
+	}
+	res.Score = board.Score()
+	return res
+}
+
+type Summary struct {
+	Games        int
+	Mean         float64
+	StdDev       float64
+	Median       float64
+	Min          int
+	Max          int
+	P10          float64
+	P25          float64
+	P75          float64
+	P90          float64
+	Skipped      int
+	MeanMoveTime time.Duration
+	MaxMoveTime  time.Duration
+	MeanNodes    float64 // per move
+}
+
+func Summarize(results []GameResult) Summary {
+	sum := Summary{Games: len(results)}
+	if len(results) == 0 {
+		return sum
+	}
+	scores := make([]float64, len(results))
+	moves, nodes := 0, 0
+	var moveTime time.Duration
+	for i, res := range results {
+		scores[i] = float64(res.Score)
+		sum.Skipped += res.Skipped
+		for _, d := range res.MoveTimes {
+			moveTime += d
+			if d > sum.MaxMoveTime {
+				sum.MaxMoveTime = d
+			}
+		}
+		moves += len(res.MoveTimes)
+		nodes += res.TotalNodes()
+	}
+	sort.Float64s(scores)
+	sum.Min, sum.Max = int(scores[0]), int(scores[len(scores)-1])
+	sum.Mean, sum.StdDev = meanStdDev(scores)
+	sum.Median = percentile(scores, 50)
+	sum.P10 = percentile(scores, 10)
+	sum.P25 = percentile(scores, 25)
+	sum.P75 = percentile(scores, 75)
+	sum.P90 = percentile(scores, 90)
+	if moves > 0 {
+		sum.MeanMoveTime = moveTime / time.Duration(moves)
+		sum.MeanNodes = float64(nodes) / float64(moves)
+	}
+	return sum
+}
+
+func meanStdDev(xs []float64) (float64, float64) {
+	if len(xs) == 0 {
+		return 0, 0
+	}
+	mean := 0.0
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	if len(xs) < 2 {
+		return mean, 0
+	}
+	variance := 0.0
+	for _, x := range xs {
+		variance += (x - mean) * (x - mean)
+	}
+	variance /= float64(len(xs) - 1)
+	return mean, math.Sqrt(variance)
+}
+
+// linearly interpolated percentile p (0-100) of sorted
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := p / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	frac := pos - float64(lo)
+	return sorted[lo] + (sorted[hi]-sorted[lo])*frac
+}
+
+func (sum Summary) Print(w io.Writer) {
+	fmt.Fprintf(w, "games:     %v (cards skipped: %v)\n", sum.Games, sum.Skipped)
+	fmt.Fprintf(w, "score:     mean %.2f  stddev %.2f  median %.1f  min %v  max %v\n", sum.Mean, sum.StdDev, sum.Median, sum.Min, sum.Max)
+	fmt.Fprintf(w, "pctiles:   p10 %.1f  p25 %.1f  p75 %.1f  p90 %.1f\n", sum.P10, sum.P25, sum.P75, sum.P90)
+	fmt.Fprintf(w, "per move:  mean %v  max %v  nodes %.1f\n", sum.MeanMoveTime, sum.MaxMoveTime, sum.MeanNodes)
+}
+
+// prints one bar per bucket of width points
+func PrintHistogram(w io.Writer, results []GameResult, width int) {
+	if len(results) == 0 || width <= 0 {
+		return
+	}
+	counts := map[int]int{}
+	lo, hi := math.MaxInt, math.MinInt
+	for _, res := range results {
+		bucket := floorDiv(res.Score, width)
+		counts[bucket]++
+		lo = min(lo, bucket)
+		hi = max(hi, bucket)
+	}
+	most := 0
+	for _, n := range counts {
+		most = max(most, n)
+	}
+	const barWidth = 40
+	for b := lo; b <= hi; b++ {
+		bar := counts[b] * barWidth / most
+		if counts[b] > 0 && bar == 0 {
+			bar = 1
+		}
+		fmt.Fprintf(w, "%4v-%-4v | %-*v %v\n", b*width, b*width+width-1, barWidth, strings.Repeat("#", bar), counts[b])
+	}
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+// one row per game
+func WriteCSV(w io.Writer, results []GameResult) error {
+	cw := csv.NewWriter(w)
+	header := []string{"game", "seed", "score", "skipped", "moves", "total_ms", "mean_move_ms", "max_move_ms", "nodes", "cards"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, res := range results {
+		var maxTime time.Duration
+		for _, d := range res.MoveTimes {
+			if d > maxTime {
+				maxTime = d
+			}
+		}
+		meanMs := 0.0
+		if len(res.MoveTimes) > 0 {
+			meanMs = ms(res.TotalTime()) / float64(len(res.MoveTimes))
+		}
+		cards := make([]string, len(res.Cards))
+		for i, num := range res.Cards {
+			cards[i] = strconv.Itoa(num)
+		}
+		row := []string{
+			strconv.Itoa(res.Game),
+			strconv.FormatInt(res.Seed, 10),
+			strconv.Itoa(res.Score),
+			strconv.Itoa(res.Skipped),
+			strconv.Itoa(len(res.MoveTimes)),
+			strconv.FormatFloat(ms(res.TotalTime()), 'f', 3, 64),
+			strconv.FormatFloat(meanMs, 'f', 3, 64),
+			strconv.FormatFloat(ms(maxTime), 'f', 3, 64),
+			strconv.Itoa(res.TotalNodes()),
+			strings.Join(cards, " "),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,80 @@
+package lib2
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestShuffledDeck(t *testing.T) {
+	seenLimit := 2
+	cards := ShuffledDeck(rand.New(rand.NewSource(1)), seenLimit)
+	if len(cards) != 20 {
+		t.Fatalf("deck size: want:%v != got:%v", 20, len(cards))
+	}
+	counts := make([]int, 10)
+	for _, num := range cards {
+		counts[num]++
+	}
+	for num, n := range counts {
+		if n != seenLimit {
+			t.Fatalf("num:%v count: want:%v != got:%v", num, seenLimit, n)
+		}
+	}
+	again := ShuffledDeck(rand.New(rand.NewSource(1)), seenLimit)
+	if !reflect.DeepEqual(cards, again) {
+		t.Fatalf("same seed should shuffle the same: %v != %v", cards, again)
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	cfg := SimConfig{Games: 3, Seed: 7, Strategy: "lookahead:1", R: 12, C: 12, SeenLimit: 2}
+	results, err := Simulate(cfg)
+	if err != nil {
+		t.Fatalf("err simulating: %v", err)
+	}
+	if len(results) != cfg.Games {
+		t.Fatalf("games: want:%v != got:%v", cfg.Games, len(results))
+	}
+	for _, res := range results {
+		if len(res.MoveTimes) != 20 || len(res.Nodes) != 20 {
+			t.Fatalf("moves recorded: want:%v != got:%v,%v", 20, len(res.MoveTimes), len(res.Nodes))
+		}
+		if res.TotalNodes() == 0 {
+			t.Fatalf("nodes should be counted")
+		}
+	}
+	// a fixed seed replays the exact same games
+	again, _ := Simulate(cfg)
+	for i := range results {
+		if results[i].Score != again[i].Score {
+			t.Fatalf("game:%v score: want:%v != got:%v", i, results[i].Score, again[i].Score)
+		}
+	}
+	sum := Summarize(results)
+	if sum.Min > int(sum.Median) || int(sum.Median) > sum.Max {
+		t.Fatalf("median:%v not within min:%v max:%v", sum.Median, sum.Min, sum.Max)
+	}
+	var buf bytes.Buffer
+	sum.Print(&buf)
+	PrintHistogram(&buf, results, 10)
+	if err := WriteCSV(&buf, results); err != nil {
+		t.Fatalf("err writing csv: %v", err)
+	}
+	if !strings.Contains(buf.String(), "game,seed,score") {
+		t.Fatalf("csv header missing:\n%v", buf.String())
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	results := []GameResult{{Score: 10}, {Score: 40}, {Score: 20}, {Score: 30}, {Score: 50}}
+	sum := Summarize(results)
+	if sum.Mean != 30 || sum.Median != 30 || sum.Min != 10 || sum.Max != 50 {
+		t.Fatalf("summary: got mean:%v median:%v min:%v max:%v", sum.Mean, sum.Median, sum.Min, sum.Max)
+	}
+	if sum.P25 != 20 || sum.P75 != 40 || sum.P10 != 14 {
+		t.Fatalf("percentiles: got p10:%v p25:%v p75:%v", sum.P10, sum.P25, sum.P75)
+	}
+}
@@ -0,0 +1,152 @@
+package lib2
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+/*
+
+	solver = perfect information search for when the whole card order is known
+	line = one placement per card, in card order, a card that fits nowhere is skipped
+	bound = most points the cards left could still make: the k-th card from now can be
+			at most k levels above the current top level, so it scores at most the best
+			the scoring gives it at any level up to top+1+k
+			the bound never underestimates, so cutting a branch whose bound can't beat the
+			best line found so far never cuts the optimal line
+	proof = if the search ran to the end, every branch was either played out or cut by
+			the bound, which proves no line beats the one returned
+
+*/
+
+type SolveConfig struct {
+	MaxNodes int // 0 means no limit, otherwise the best line found so far is returned unproven
+}
+
+type Proof struct {
+	Nodes     int  // positions visited
+	Pruned    int  // branches cut by the bound
+	RootBound int  // bound on the points of any line, before searching
+	Complete  bool // search ran to the end, Value is optimal
+}
+
+type Solution struct {
+	Cards  []int
+	Moves  []Move // the optimal line, one placement per card that fits, in card order
+	Points []int  // points made by each move
+	Value  int    // points made by Moves
+	Proof  Proof
+}
+
+type solver struct {
+	board    *Board
+	cards    []int
+	cfg      SolveConfig
+	proof    Proof
+	best     int
+	found    bool // best is the value of a line, nothing is pruned before there is one
+	bestLine []Move
+	line     []Move
+}
+
+// best line for playing cards in order starting from board, board itself is not changed
+func (board *Board) Solve(cards []int, cfg SolveConfig) (*Solution, error) {
+	seen := make([]int, len(board.seen))
+	copy(seen, board.seen)
+	for _, num := range cards {
+		if num < 0 || num >= len(board.rules.Shapes.Shapes) {
+			return nil, fmt.Errorf("num:%v is not a number", num)
+		}
+		seen[num]++
+		if seen[num] > board.copies(num) {
+			return nil, &SeenError{Num: num, Copies: board.copies(num)}
+		}
+	}
+	s := &solver{board: board, cards: cards, cfg: cfg}
+	top := topLevel(board.flat)
+	s.proof.RootBound = s.bound(0, top)
+	s.proof.Complete = s.search(board.flat, 0, 0, top)
+	sol := &Solution{Cards: cards, Moves: s.bestLine, Value: s.best, Proof: s.proof}
+	for _, m := range sol.Moves {
+		sol.Points = append(sol.Points, board.score(m.Num, m.Level))
+	}
+	return sol, nil
+}
+
+// highest level in flat, -1 when nothing has been placed
+func topLevel(flat *Layer) int8 {
+	top := EMPTY
+	for _, level := range flat.cells {
+		if level > top {
+			top = level
+		}
+	}
+	return top
+}
+
+func (s *solver) bound(k int, top int8) int {
+	b := 0
+	for j := k; j < len(s.cards); j++ {
+		b += s.board.bestScore(s.cards[j], top+1+int8(j-k))
+	}
+	return b
+}
+
+// returns false once the node limit is hit
+func (s *solver) search(flat *Layer, k int, points int, top int8) bool {
+	s.proof.Nodes++
+	if s.cfg.MaxNodes > 0 && s.proof.Nodes > s.cfg.MaxNodes {
+		return false
+	}
+	if k == len(s.cards) {
+		if !s.found || points > s.best {
+			s.best, s.found = points, true
+			s.bestLine = append(s.bestLine[:0], s.line...)
+		}
+		return true
+	}
+	if s.found && points+s.bound(k, top) <= s.best {
+		s.proof.Pruned++
+		return true
+	}
+	num := s.cards[k]
+	moves := s.board.legalMovesOn(flat, num)
+	if len(moves) == 0 {
+		return s.search(flat, k+1, points+s.board.rules.Scoring.Unused, top)
+	}
+	// high placements first, good lines found early make the bound cut more
+	sort.SliceStable(moves, func(i, j int) bool {
+		return moves[i].Level > moves[j].Level
+	})
+	for _, m := range moves {
+		newFlat := copyFlat(flat)
+		s.board.placeOnFlat(newFlat, m)
+		s.line = append(s.line, m)
+		complete := s.search(newFlat, k+1, points+s.board.score(num, m.Level), max8(top, m.Level))
+		s.line = s.line[:len(s.line)-1]
+		if !complete {
+			return false
+		}
+	}
+	return true
+}
+
+func max8(a, b int8) int8 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func (sol *Solution) Print(w io.Writer) {
+	for i, m := range sol.Moves {
+		fmt.Fprintf(w, "  %v at %v: %v points\n", m.Num, placement(m), sol.Points[i])
+	}
+	fmt.Fprintf(w, "value %v  nodes %v  pruned %v  root bound %v\n", sol.Value, sol.Proof.Nodes, sol.Proof.Pruned, sol.Proof.RootBound)
+	if sol.Proof.Complete {
+		fmt.Fprintln(w, "optimal: every other line was searched or cut by the bound")
+	} else {
+		fmt.Fprintln(w, "not proven: node limit reached, this is the best line found so far")
+	}
+}
@@ -0,0 +1,116 @@
+package lib2
+
+import (
+	"math"
+	"testing"
+)
+
+// plays out every line without cutting anything
+func bruteForce(board *Board, flat *Layer, cards []int) int {
+	if len(cards) == 0 {
+		return 0
+	}
+	moves := board.legalMovesOn(flat, cards[0])
+	if len(moves) == 0 {
+		return board.rules.Scoring.Unused + bruteForce(board, flat, cards[1:])
+	}
+	best := math.MinInt
+	for _, m := range moves {
+		newFlat := copyFlat(flat)
+		board.placeOnFlat(newFlat, m)
+		best = max(best, board.score(m.Num, m.Level)+bruteForce(board, newFlat, cards[1:]))
+	}
+	return best
+}
+
+func TestSolveIsOptimal(t *testing.T) {
+	tests := []struct {
+		cards []int
+	}{
+		{cards: []int{2, 4, 8, 6}},
+		{cards: []int{9, 8, 7, 1}},
+		{cards: []int{0, 5, 9, 3}},
+	}
+	for _, tt := range tests {
+		R, C := 6, 8
+		board := newBoardRC(R, C, 1)
+		sol, err := board.Solve(tt.cards, SolveConfig{})
+		if err != nil {
+			t.Fatalf("err solving: %v", err)
+		}
+		if !sol.Proof.Complete {
+			t.Fatalf("cards:%v search should complete", tt.cards)
+		}
+		if want := bruteForce(board, board.flat, tt.cards); sol.Value != want {
+			t.Fatalf("cards:%v value: want:%v != got:%v", tt.cards, want, sol.Value)
+		}
+		if sol.Value > sol.Proof.RootBound {
+			t.Fatalf("bound:%v is not an upper bound of value:%v", sol.Proof.RootBound, sol.Value)
+		}
+		// the line replays to the same score on a real board
+		for _, m := range sol.Moves {
+			if err := board.ApplyMove(m); err != nil {
+				t.Fatalf("line is not legal: %v", err)
+			}
+		}
+		if board.Score() != sol.Value {
+			t.Fatalf("replayed score: want:%v != got:%v", sol.Value, board.Score())
+		}
+	}
+}
+
+func TestSolveBeatsLookahead(t *testing.T) {
+	cards := []int{2, 4, 8, 6}
+	R, C := 4, 7
+	board := newBoardRC(R, C, 1)
+	for _, num := range cards {
+		if err, _ := board.ApplyBestMove(num, 2); err != nil {
+			t.Fatalf("err applying best move: %v", err)
+		}
+	}
+	sol, err := newBoardRC(R, C, 1).Solve(cards, SolveConfig{})
+	if err != nil {
+		t.Fatalf("err solving: %v", err)
+	}
+	if sol.Value < board.Score() {
+		t.Fatalf("optimal:%v can't be worse than lookahead:%v", sol.Value, board.Score())
+	}
+}
+
+func TestSolveNodeLimit(t *testing.T) {
+	board := newBoardRC(12, 12, 2)
+	sol, err := board.Solve([]int{5, 9, 8, 7, 6, 2}, SolveConfig{MaxNodes: 50})
+	if err != nil {
+		t.Fatalf("err solving: %v", err)
+	}
+	if sol.Proof.Complete {
+		t.Fatalf("search should stop at the node limit")
+	}
+	if _, err := board.Solve([]int{5, 5, 5}, SolveConfig{}); err == nil {
+		t.Fatalf("5 can't be drawn 3 times")
+	}
+}
+
+// every line loses points, the best one still has to be found
+func TestSolveNegativeScoring(t *testing.T) {
+	scoring, err := ScoringByName("standard+area:-1")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	for _, cards := range [][]int{{5, 9}, {2, 4, 8}} {
+		board, err := NewBoardWithRules(&Rules{Name: "negative", R: 6, C: 8, Scoring: scoring, Deck: UniformDeck(len(NUMBER), 1)})
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		sol, err := board.Solve(cards, SolveConfig{})
+		if err != nil {
+			t.Fatalf("err solving: %v", err)
+		}
+		if want := bruteForce(board, board.flat, cards); sol.Value != want || want >= 0 {
+			t.Fatalf("cards:%v value: want:%v (below 0) != got:%v", cards, want, sol.Value)
+		}
+		if !sol.Proof.Complete || len(sol.Moves) != len(cards) {
+			t.Fatalf("cards:%v proof:%+v moves:%v", cards, sol.Proof, sol.Moves)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package lib2
+
+import "nmbr9/core"
+
+/*
+
+	Layer is the lib2 storage for core, cells is one flat slice row after row and
+	the bounding box grows with every tile set so nothing has to be scanned for it
+
+*/
+
+// an empty layer or flat for core, every cell EMPTY
+func NewStorage(rows, cols int) core.Storage {
+	return makeLayerRC(rows, cols)
+}
+
+// an empty board for the core diff harness, the official rules with copies of
+// every number
+func NewBackend(rows, cols, copies int) core.Backend {
+	return newBoardRC(rows, cols, copies)
+}
+
+// a copy of the flat, every cell the level of its top tile
+func (board *Board) Flat() core.Storage {
+	return copyFlat(board.flat)
+}
+
+func (layer *Layer) Size() (int, int) {
+	return layer.R, layer.C
+}
+
+func (layer *Layer) Get(r, c int) int8 {
+	return layer.cells[r*layer.C+c]
+}
+
+func (layer *Layer) Set(r, c int, v int8) {
+	layer.cells[r*layer.C+c] = v
+	if v == EMPTY {
+		return
+	}
+	layer.BB_TL_R = min(layer.BB_TL_R, r)
+	layer.BB_TL_C = min(layer.BB_TL_C, c)
+	layer.BB_BR_R = max(layer.BB_BR_R, r)
+	layer.BB_BR_C = max(layer.BB_BR_C, c)
+}
+
+func (layer *Layer) Row(r int) []int8 {
+	return layer.cells[r*layer.C : (r+1)*layer.C]
+}
+
+func (layer *Layer) Bounds() (int, int, int, int) {
+	return layer.BB_TL_R, layer.BB_TL_C, layer.BB_BR_R, layer.BB_BR_C
+}
+
+// merges other onto layer, only the bounding box of other is looked at
+func (layer *Layer) Flatten(other core.Storage, level int8) {
+	tlR, tlC, brR, brC := other.Bounds()
+	for r := tlR; r <= brR; r++ {
+		cells := other.Row(r)
+		for c := tlC; c <= brC; c++ {
+			if cells[c] != EMPTY {
+				layer.cells[r*layer.C+c] = level
+			}
+		}
+	}
+	// update bounding box of layer
+	if tlR <= brR {
+		layer.BB_TL_R = min(layer.BB_TL_R, tlR)
+		layer.BB_TL_C = min(layer.BB_TL_C, tlC)
+		layer.BB_BR_R = max(layer.BB_BR_R, brR)
+		layer.BB_BR_C = max(layer.BB_BR_C, brC)
+	}
+}
+
+func (layer *Layer) Copy() core.Storage {
+	return copyFlat(layer)
+}
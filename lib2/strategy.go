@@ -0,0 +1,416 @@
+package lib2
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"nmbr9/core"
+)
+
+/*
+
+	strategy = a way of picking where the drawn number goes
+	remaining = how many of each number are still in the deck, not counting the drawn one
+
+	strategies are picked by name, with optional ':' separated arguments
+		random
+		greedy
+		lookahead[:steps[:seenLimit]]	seenLimit overrides how many copies the search assumes
+		expectimax[:steps]
+		montecarlo[:rollouts]
+		mcts[:iterations[:milliseconds]]	stops at whichever budget runs out first
+
+*/
+
+const StrategyNames = "random, greedy, lookahead[:steps[:seenLimit]], expectimax[:steps], montecarlo[:rollouts], mcts[:iterations[:ms]]"
+
+type Strategy interface {
+	Name() string
+	ChooseMove(board *Board, num int, remaining []int) (Move, error)
+}
+
+func StrategyByName(spec string, seed int64) (Strategy, error) {
+	parts := strings.Split(spec, ":")
+	args := make([]int, len(parts)-1)
+	for i, part := range parts[1:] {
+		arg, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("strategy:%v has bad argument:%v", spec, part)
+		}
+		if arg < 1 {
+			return nil, fmt.Errorf("strategy:%v argument must be at least 1", spec)
+		}
+		args[i] = arg
+	}
+	arg := func(i, def int) int {
+		if i < len(args) {
+			return args[i]
+		}
+		return def
+	}
+	switch parts[0] {
+	case "random":
+		return NewRandomStrategy(seed), nil
+	case "greedy":
+		return GreedyStrategy{}, nil
+	case "lookahead":
+		return LookaheadStrategy{Steps: arg(0, 2), SeenLimit: arg(1, 0)}, nil
+	case "expectimax":
+		return ExpectimaxStrategy{Steps: arg(0, 2)}, nil
+	case "montecarlo":
+		return NewMonteCarloStrategy(arg(0, 16), seed), nil
+	case "mcts":
+		cfg := DefaultMCTSConfig()
+		cfg.Iterations = arg(0, cfg.Iterations)
+		cfg.Budget = time.Duration(arg(1, 0)) * time.Millisecond
+		return NewMCTSStrategy(cfg, seed), nil
+	}
+	return nil, fmt.Errorf("unknown strategy: %v", spec)
+}
+
+// draws num and places it where strategy wants it
+func (board *Board) Play(strategy Strategy, num int) (Move, error) {
+	if err := board.checkNum(num); err != nil {
+		return Move{}, err
+	}
+	remaining := board.Remaining()
+	remaining[num]--
+	board.lastSearch = SearchInfo{}
+	move, err := strategy.ChooseMove(board, num, remaining)
+	if err != nil {
+		// the card was drawn even though it fits nowhere
+		board.discard(num)
+		return Move{}, err
+	}
+	return move, board.ApplyMove(move)
+}
+
+// how many of each number have not been placed yet
+func (board *Board) Remaining() []int {
+	remaining := make([]int, len(board.seen))
+	for i, n := range board.seen {
+		remaining[i] = board.copies(i) - n
+	}
+	return remaining
+}
+
+// places num at (m.Row,m.Col) if that is a valid move, the level is worked out from flat
+func (board *Board) ApplyMove(m Move) error {
+	if err := board.applyMove(m); err != nil {
+		return err
+	}
+	board.debugValidate()
+	return nil
+}
+
+// ApplyMove without the debug check
+func (board *Board) applyMove(m Move) error {
+	if err := board.checkNum(m.Num); err != nil {
+		return err
+	}
+	o, err := board.canonical(m.Num, m.Orient)
+	if err != nil {
+		return err
+	}
+	if len(board.layers) == 0 {
+		return board.setBaseLayer(m.Num, o)
+	}
+	if m.Row < 0 || m.Col < 0 {
+		return fmt.Errorf("num:%v can't be placed at (%v,%v)", m.Num, m.Row, m.Col)
+	}
+	valid, level := board.isValid(board.flat, m.Num, o, m.Row, m.Col)
+	if !valid {
+		return fmt.Errorf("num:%v can't be placed at (%v,%v)", m.Num, m.Row, m.Col)
+	}
+	return board.putNumberAtLayer(level, m.Num, o, m.Row, m.Col)
+}
+
+// every valid placement of num on the board, none if num is not a number
+func (board *Board) LegalMoves(num int) []Move {
+	if num < 0 || num >= len(board.rules.Shapes.Shapes) {
+		return []Move{}
+	}
+	return board.legalMovesOn(board.flat, num)
+}
+
+// every valid placement of num on flat, scanning only around the bounding box
+// the first number always goes in the middle of an empty board, once per orientation
+func (board *Board) legalMovesOn(flat *Layer, num int) []Move {
+	if flat.BB_TL_R > flat.BB_BR_R {
+		moves := []Move{}
+		for _, o := range board.orients(num) {
+			r, c := board.middle(num, o)
+			moves = append(moves, Move{Num: num, Row: r, Col: c, Level: 0, Orient: o})
+		}
+		return moves
+	}
+	return board.search().LegalMoves(flat, num)
+}
+
+// writes the level of m into flat without keeping a layer around,
+// used by searches that only need the shape of the stack
+func (board *Board) placeOnFlat(flat *Layer, m Move) {
+	core.Place(flat, board.orient(m.Num, m.Orient), m.Row, m.Col, m.Level)
+}
+
+func (board *Board) Clone() *Board {
+	layers := make([]*Layer, len(board.layers), cap(board.layers))
+	for i, layer := range board.layers {
+		layers[i] = copyLayer(layer)
+	}
+	seen := make([]int, len(board.seen))
+	copy(seen, board.seen)
+	fanout := make([]int, len(board.fanout))
+	copy(fanout, board.fanout)
+	history := make([]Move, len(board.history), cap(board.history))
+	copy(history, board.history)
+	drawn := make([]int, len(board.drawn), cap(board.drawn))
+	copy(drawn, board.drawn)
+	return &Board{
+		R:       board.R,
+		C:       board.C,
+		layers:  layers,
+		flat:    copyFlat(board.flat),
+		seen:    seen,
+		fanout:  fanout,
+		history: history,
+		drawn:   drawn,
+		rules:   board.rules,
+		weights: board.weights,
+		endgame: board.endgame,
+	}
+}
+
+func noValidMoves(num int) error {
+	return fmt.Errorf("no valid moves for num: %v", num)
+}
+
+// picks any valid move, uniformly
+type RandomStrategy struct {
+	rng *rand.Rand
+}
+
+func NewRandomStrategy(seed int64) *RandomStrategy {
+	return &RandomStrategy{rng: rand.New(rand.NewSource(seed))}
+}
+
+func (s *RandomStrategy) Name() string {
+	return "random"
+}
+
+func (s *RandomStrategy) ChooseMove(board *Board, num int, remaining []int) (Move, error) {
+	moves := board.LegalMoves(num)
+	board.fanout = []int{len(moves)}
+	if len(moves) == 0 {
+		return Move{}, noValidMoves(num)
+	}
+	return moves[s.rng.Intn(len(moves))], nil
+}
+
+// picks the move that scores the most right now, ignores the deck
+type GreedyStrategy struct{}
+
+func (s GreedyStrategy) Name() string {
+	return "greedy"
+}
+
+func (s GreedyStrategy) ChooseMove(board *Board, num int, remaining []int) (Move, error) {
+	board.fanout = []int{1}
+	if len(board.layers) == 0 {
+		return board.legalMovesOn(board.flat, num)[0], nil
+	}
+	move := board.findBestMove(board.flat, num)
+	if valid, _ := board.isValid(board.flat, num, move.Orient, move.Row, move.Col); !valid {
+		return Move{}, noValidMoves(num)
+	}
+	return move, nil
+}
+
+// the search ApplyBestMove uses: best sum of scores over the next Steps draws,
+// assuming the board's deck, or SeenLimit copies of every number
+type LookaheadStrategy struct {
+	Steps     int
+	SeenLimit int // 0 means the board's own deck
+}
+
+func (s LookaheadStrategy) Name() string {
+	if s.SeenLimit > 0 {
+		return fmt.Sprintf("lookahead:%v:%v", s.Steps, s.SeenLimit)
+	}
+	return fmt.Sprintf("lookahead:%v", s.Steps)
+}
+
+func (s LookaheadStrategy) ChooseMove(board *Board, num int, remaining []int) (Move, error) {
+	if len(board.layers) == 0 {
+		board.fanout = []int{1}
+		return board.legalMovesOn(board.flat, num)[0], nil
+	}
+	if board.inEndgame(num) {
+		info, err := board.solveEndgame(num)
+		return info.Move, err
+	}
+	deck := board.rules.Deck
+	if s.SeenLimit > 0 {
+		deck = UniformDeck(len(board.seen), s.SeenLimit)
+	}
+	seen := make([]int, len(board.seen))
+	copy(seen, board.seen)
+	board.fanout = make([]int, s.Steps)
+	move, maxScore, err := board.findBestMoveV2(board.flat, seen, deck, num, s.Steps)
+	if err != nil {
+		return Move{}, err
+	}
+	board.lastSearch = SearchInfo{Move: move, Value: float64(maxScore)}
+	return move, nil
+}
+
+// best expected sum of scores over the next Steps draws, where every draw is
+// weighted by how many copies of it are left in the deck
+type ExpectimaxStrategy struct {
+	Steps int
+}
+
+func (s ExpectimaxStrategy) Name() string {
+	return fmt.Sprintf("expectimax:%v", s.Steps)
+}
+
+func (s ExpectimaxStrategy) ChooseMove(board *Board, num int, remaining []int) (Move, error) {
+	if board.inEndgame(num) {
+		info, err := board.solveEndgame(num)
+		return info.Move, err
+	}
+	board.fanout = make([]int, s.Steps)
+	left := make([]int, len(remaining))
+	copy(left, remaining)
+	move, value, err := board.expectimax(board.flat, left, num, s.Steps)
+	if err == nil {
+		board.lastSearch = SearchInfo{Move: move, Value: value}
+	}
+	return move, err
+}
+
+func (board *Board) expectimax(flat *Layer, remaining []int, num int, steps int) (Move, float64, error) {
+	board.fanout[len(board.fanout)-steps]++
+	moves := board.legalMovesOn(flat, num)
+	if len(moves) == 0 {
+		return Move{}, 0, noValidMoves(num)
+	}
+	total := 0
+	for _, n := range remaining {
+		total += n
+	}
+	var best Move
+	bestValue := math.Inf(-1)
+	for _, m := range moves {
+		value := float64(board.score(num, m.Level))
+		if steps == 1 && board.weights != nil && total > 0 {
+			// horizon: also count what the position is worth
+			newFlat := copyFlat(flat)
+			board.placeOnFlat(newFlat, m)
+			value += board.weights.apply(board.features(newFlat, remaining))
+		}
+		if steps > 1 && total > 0 {
+			newFlat := copyFlat(flat)
+			board.placeOnFlat(newFlat, m)
+			for i, n := range remaining {
+				if n == 0 {
+					continue
+				}
+				remaining[i]--
+				_, future, err := board.expectimax(newFlat, remaining, i, steps-1)
+				remaining[i]++
+				if err != nil {
+					// the card fits nowhere
+					future = float64(board.rules.Scoring.Unused)
+				}
+				value += float64(n) / float64(total) * future
+			}
+		}
+		if value > bestValue {
+			bestValue = value
+			best = m
+		}
+	}
+	return best, bestValue, nil
+}
+
+// tries every move and plays the rest of the game Rollouts times with random
+// draws from the deck and greedy placements, keeps the move with the best mean
+type MonteCarloStrategy struct {
+	Rollouts int
+	rng      *rand.Rand
+}
+
+func NewMonteCarloStrategy(rollouts int, seed int64) *MonteCarloStrategy {
+	return &MonteCarloStrategy{Rollouts: rollouts, rng: rand.New(rand.NewSource(seed))}
+}
+
+func (s *MonteCarloStrategy) Name() string {
+	return fmt.Sprintf("montecarlo:%v", s.Rollouts)
+}
+
+func (s *MonteCarloStrategy) ChooseMove(board *Board, num int, remaining []int) (Move, error) {
+	board.fanout = []int{0}
+	moves := board.LegalMoves(num)
+	if len(moves) == 0 {
+		return Move{}, noValidMoves(num)
+	}
+	if len(moves) == 1 {
+		return moves[0], nil
+	}
+	cards := deckCards(remaining)
+	var best Move
+	bestValue := math.Inf(-1)
+	for _, m := range moves {
+		total := 0
+		for i := 0; i < s.Rollouts; i++ {
+			flat := copyFlat(board.flat)
+			board.placeOnFlat(flat, m)
+			s.rng.Shuffle(len(cards), func(a, b int) {
+				cards[a], cards[b] = cards[b], cards[a]
+			})
+			total += board.score(num, m.Level) + board.rollout(flat, cards)
+		}
+		if value := float64(total) / float64(s.Rollouts); value > bestValue {
+			bestValue = value
+			best = m
+		}
+	}
+	return best, nil
+}
+
+// plays cards onto flat greedily and returns the points scored
+func (board *Board) rollout(flat *Layer, cards []int) int {
+	total := 0
+	for _, num := range cards {
+		moves := board.legalMovesOn(flat, num)
+		board.fanout[0] += len(moves)
+		if len(moves) == 0 {
+			continue
+		}
+		best, bestScore := moves[0], board.score(num, moves[0].Level)
+		for _, m := range moves[1:] {
+			if score := board.score(num, m.Level); score > bestScore {
+				best, bestScore = m, score
+			}
+		}
+		board.placeOnFlat(flat, best)
+		total += bestScore
+	}
+	return total
+}
+
+// expands counts per number into a list of cards
+func deckCards(remaining []int) []int {
+	cards := []int{}
+	for num, n := range remaining {
+		for i := 0; i < n; i++ {
+			cards = append(cards, num)
+		}
+	}
+	return cards
+}
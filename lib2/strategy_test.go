@@ -0,0 +1,169 @@
+package lib2
+
+import (
+	"testing"
+)
+
+func TestStrategyByName(t *testing.T) {
+	tests := []struct {
+		spec     string
+		wantName string
+		wantErr  bool
+	}{
+		{spec: "random", wantName: "random"},
+		{spec: "greedy", wantName: "greedy"},
+		{spec: "lookahead", wantName: "lookahead:2"},
+		{spec: "lookahead:3", wantName: "lookahead:3"},
+		{spec: "lookahead:3:1", wantName: "lookahead:3:1"},
+		{spec: "expectimax:2", wantName: "expectimax:2"},
+		{spec: "montecarlo:4", wantName: "montecarlo:4"},
+		{spec: "lookahead:0", wantErr: true},
+		{spec: "lookahead:x", wantErr: true},
+		{spec: "minimax", wantErr: true},
+	}
+	for _, tt := range tests {
+		strategy, err := StrategyByName(tt.spec, 1)
+		if tt.wantErr {
+			if err == nil {
+				t.Fatalf("spec:%v should fail", tt.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("spec:%v err: %v", tt.spec, err)
+		}
+		if strategy.Name() != tt.wantName {
+			t.Fatalf("name: want:%v != got:%v", tt.wantName, strategy.Name())
+		}
+	}
+}
+
+func TestStrategiesPlayLegalGames(t *testing.T) {
+	cards := []int{3, 8, 1, 9, 0, 5, 2, 7, 6, 4}
+	for _, spec := range []string{"random", "greedy", "lookahead:2", "expectimax:2", "montecarlo:2"} {
+		strategy, err := StrategyByName(spec, 1)
+		if err != nil {
+			t.Fatalf("spec:%v err: %v", spec, err)
+		}
+		board := newBoardRC(12, 12, 1)
+		lost := 0
+		for _, num := range cards {
+			fits := len(board.LegalMoves(num)) > 0
+			move, err := board.Play(strategy, num)
+			if err != nil && fits {
+				t.Fatalf("%v: err playing num:%v: %v", spec, num, err)
+			}
+			if err != nil {
+				// random placements can leave no room, the card is lost
+				lost++
+				continue
+			}
+			if move.Num != num {
+				t.Fatalf("%v: played num:%v for card:%v", spec, move.Num, num)
+			}
+		}
+		if len(board.History()) != len(cards)-lost {
+			t.Fatalf("%v: placed %v of %v cards", spec, len(board.History()), len(cards)-lost)
+		}
+	}
+}
+
+func TestLookaheadStrategyMatchesApplyBestMove(t *testing.T) {
+	cards := []int{2, 4, 8, 6}
+	steps := 2
+	seenLimit := 1
+	R, C := 4, 7
+	want := newBoardRC(R, C, seenLimit)
+	got := newBoardRC(R, C, seenLimit)
+	for _, num := range cards {
+		if err, _ := want.ApplyBestMove(num, steps); err != nil {
+			t.Fatalf("err applying best move: %v", err)
+		}
+		if _, err := got.Play(LookaheadStrategy{Steps: steps}, num); err != nil {
+			t.Fatalf("err playing: %v", err)
+		}
+	}
+	if want.History()[3] != got.History()[3] || want.Score() != got.Score() {
+		t.Fatalf("want:%v != got:%v", want.History(), got.History())
+	}
+}
+
+func TestExpectimax1StepIsGreedy(t *testing.T) {
+	R, C := 4, 7
+	board := newBoardRC(R, C, 1)
+	board.putNumberAtLayer(0, 9, 0, 0, 1)
+	/*
+		setup:    best:
+		.999...   .988...
+		.999...   .988...
+		.99....   .88....
+		.99....   .88....
+	*/
+	move, err := ExpectimaxStrategy{Steps: 1}.ChooseMove(board, 8, board.Remaining())
+	if err != nil {
+		t.Fatalf("err choosing move: %v", err)
+	}
+	if want := (Move{Num: 8, Row: 0, Col: 1, Level: 1}); move != want {
+		t.Fatalf("want:%v != got:%v", want, move)
+	}
+}
+
+func TestApplyMove(t *testing.T) {
+	R, C := 4, 7
+	board := newBoardRC(R, C, 1)
+	if err := board.ApplyMove(Move{Num: 9}); err != nil {
+		t.Fatalf("first move should go in the middle: %v", err)
+	}
+	// 9 sits at (0,2), 8 at (0,3) would cross levels
+	if err := board.ApplyMove(Move{Num: 8, Row: 0, Col: 3}); err == nil {
+		t.Fatalf("8 at (0,3) should not be valid")
+	}
+	if err := board.ApplyMove(Move{Num: 8, Row: 0, Col: 2}); err != nil {
+		t.Fatalf("8 at (0,2) should be valid: %v", err)
+	}
+	if got := board.History()[1].Level; got != 1 {
+		t.Fatalf("level: want:%v != got:%v", 1, got)
+	}
+	if err := board.ApplyMove(Move{Num: 8, Row: 0, Col: 2}); err == nil {
+		t.Fatalf("8 has already been seen")
+	}
+}
+
+func TestClone(t *testing.T) {
+	board := newBoardRC(12, 12, 2)
+	board.ApplyBestMove(9, 1)
+	clone := board.Clone()
+	clone.ApplyBestMove(8, 1)
+	if len(board.History()) != 1 || board.seen[8] != 0 {
+		t.Fatalf("clone should not change the original")
+	}
+	if len(clone.History()) != 2 {
+		t.Fatalf("clone history: want:%v != got:%v", 2, len(clone.History()))
+	}
+}
+
+func TestRolloutRanksByScore(t *testing.T) {
+	board := NewBoard()
+	for _, num := range []int{5, 9, 0} {
+		board.ApplyBestMove(num, 1)
+	}
+	// level 0 pays five times what stacking does
+	scoring, err := ScoringByName("table:5,1")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	board.SetScoring(scoring)
+	moves := board.LegalMoves(8)
+	want, stacked := 0, false
+	for _, m := range moves {
+		want = max(want, board.score(8, m.Level))
+		stacked = stacked || m.Level > 0
+	}
+	if !stacked {
+		t.Fatalf("8 can't be stacked: %v", moves)
+	}
+	board.fanout = []int{0}
+	if got := board.rollout(copyFlat(board.flat), []int{8}); got != want {
+		t.Fatalf("rollout scored %v, the best move scores %v", got, want)
+	}
+}
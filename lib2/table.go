@@ -0,0 +1,338 @@
+package lib2
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+/*
+
+	table = several players sharing one deck: every drawn card is placed by every player
+			on their own board, the deck and seen are the table's, not the players'
+	player = a name and a board, placed by a strategy or by hand when it has none
+	rules = the same for every player, the table's deck is the deck of the rules
+	turn = one card: engine players place it right away, the table then waits for every
+		   manual player, once all of them have placed it (or it fits nowhere on their
+		   board) the card counts as seen and the next one can be drawn
+
+*/
+
+const Manual = "manual"
+
+type Player struct {
+	Name     string
+	Strategy Strategy // nil when the player places by hand
+	Board    *Board
+}
+
+func (p *Player) StrategyName() string {
+	if p.Strategy == nil {
+		return Manual
+	}
+	return p.Strategy.Name()
+}
+
+type Table struct {
+	Players []*Player
+	R       int
+	C       int
+	rules   Rules
+	seen    []int
+	deck    Deck
+	drawn   []int
+	card    int       // the card being placed, -1 between turns
+	waiting []*Player // manual players that still have to place card
+}
+
+// strategies[i] places for names[i], nil for a manual player, played by the official rules
+func NewTable(names []string, strategies []Strategy) (*Table, error) {
+	return NewTableWithRules(OfficialRules(), names, strategies)
+}
+
+// a table where every player plays by rules
+func NewTableWithRules(rules *Rules, names []string, strategies []Strategy) (*Table, error) {
+	if len(names) == 0 {
+		return nil, fmt.Errorf("a table needs at least 1 player")
+	}
+	if len(names) != len(strategies) {
+		return nil, fmt.Errorf("%v players but %v strategies", len(names), len(strategies))
+	}
+	table := &Table{R: rules.R, C: rules.C, card: -1}
+	for i, name := range names {
+		if name == "" {
+			return nil, fmt.Errorf("player %v has no name", i+1)
+		}
+		if table.Player(name) != nil {
+			return nil, fmt.Errorf("two players are called:%v", name)
+		}
+		board, err := NewBoardWithRules(rules)
+		if err != nil {
+			return nil, err
+		}
+		table.Players = append(table.Players, &Player{Name: name, Strategy: strategies[i], Board: board})
+	}
+	// every board filled in the same shapes, deck and scoring
+	table.rules = *table.Players[0].Board.Rules()
+	table.deck = table.rules.Deck
+	table.seen = make([]int, len(table.deck))
+	return table, nil
+}
+
+func newTableRC(rows, cols int, deck Deck, names []string, strategies []Strategy) (*Table, error) {
+	return NewTableWithRules(&Rules{Name: "official", R: rows, C: cols, Deck: deck}, names, strategies)
+}
+
+// the rules every player plays by
+func (table *Table) Rules() *Rules {
+	rules := table.rules
+	return &rules
+}
+
+// the player called name, nil if there is none
+func (table *Table) Player(name string) *Player {
+	for _, p := range table.Players {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// how many of each number are still in the shared deck
+func (table *Table) Remaining() []int {
+	remaining := make([]int, len(table.seen))
+	for i, n := range table.seen {
+		remaining[i] = table.deck[i] - n
+	}
+	return remaining
+}
+
+// the card being placed, false between turns
+func (table *Table) Card() (int, bool) {
+	return table.card, table.card >= 0
+}
+
+// manual players that still have to place the current card
+func (table *Table) Waiting() []*Player {
+	return append([]*Player{}, table.waiting...)
+}
+
+// draws num for everyone, engine players place it right away
+func (table *Table) Draw(num int) error {
+	if table.card >= 0 {
+		return fmt.Errorf("num:%v is still being placed by %v", table.card, table.waitingNames())
+	}
+	if num < 0 || num >= len(table.deck) {
+		return fmt.Errorf("num:%v is not a number", num)
+	}
+	if table.seen[num] >= table.deck[num] {
+		return &SeenError{Num: num, Copies: table.deck[num]}
+	}
+	table.card = num
+	table.drawn = append(table.drawn, num)
+	table.waiting = table.waiting[:0]
+	for _, p := range table.Players {
+		if p.Strategy != nil {
+			// a card that fits nowhere is lost, the board records it as drawn
+			p.Board.Play(p.Strategy, num)
+			continue
+		}
+		if p.Board.Discard(num) == nil {
+			continue
+		}
+		table.waiting = append(table.waiting, p)
+	}
+	table.endTurn()
+	return nil
+}
+
+// true once every card of the shared deck has been drawn and placed
+func (table *Table) GameOver() bool {
+	if table.card >= 0 {
+		return false
+	}
+	for _, n := range table.Remaining() {
+		if n > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// places the current card in orientation o for the manual player called name
+func (table *Table) Place(name string, row, col, o int) error {
+	if table.card < 0 {
+		return fmt.Errorf("no card has been drawn")
+	}
+	i := -1
+	for j, p := range table.waiting {
+		if p.Name == name {
+			i = j
+		}
+	}
+	if i < 0 {
+		return fmt.Errorf("player:%v is not waiting to place a card", name)
+	}
+	if err := table.waiting[i].Board.ApplyMove(Move{Num: table.card, Row: row, Col: col, Orient: o}); err != nil {
+		return err
+	}
+	table.waiting = append(table.waiting[:i], table.waiting[i+1:]...)
+	table.endTurn()
+	return nil
+}
+
+// once nobody is waiting the card is seen, every board gets the table's deck
+func (table *Table) endTurn() {
+	if len(table.waiting) > 0 {
+		return
+	}
+	table.seen[table.card]++
+	table.card = -1
+	for _, p := range table.Players {
+		copy(p.Board.seen, table.seen)
+	}
+}
+
+func (table *Table) waitingNames() string {
+	names := make([]string, len(table.waiting))
+	for i, p := range table.waiting {
+		names[i] = p.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// players from the highest score down, ties keep table order
+func (table *Table) Standings() []*Player {
+	players := append([]*Player{}, table.Players...)
+	sort.SliceStable(players, func(i, j int) bool {
+		return players[i].Board.Score() > players[j].Board.Score()
+	})
+	return players
+}
+
+func (table *Table) PrintScores(w io.Writer) {
+	fmt.Fprintf(w, "cards drawn %v  left %v\n", len(table.drawn), len(deckCards(table.Remaining())))
+	for i, p := range table.Standings() {
+		fmt.Fprintf(w, "%2v. %-12v %4v  %v\n", i+1, p.Name, p.Board.Score(), p.StrategyName())
+	}
+}
+
+// the board of every player, one after the other
+func (table *Table) PrintViews(w io.Writer) {
+	for _, p := range table.Players {
+		fmt.Fprintf(w, "%v%v%v  %v points\n", Bold, p.Name, Reset, p.Board.Score())
+		fmt.Fprint(w, p.Board.RenderIso())
+	}
+}
+
+// the rules are kept the way GameRecord keeps them
+type TableRecord struct {
+	Rules        string `json:",omitempty"`
+	R            int
+	C            int
+	SeenLimit    int `json:",omitempty"` // copies of every number in records without a Deck
+	Deck         Deck
+	Shapes       *ShapeSet    `json:",omitempty"`
+	Scoring      *Scoring     `json:",omitempty"`
+	Orientations Orientations `json:",omitempty"`
+	Support      Support      `json:",omitempty"`
+	Adjacency    Adjacency    `json:",omitempty"`
+	Cards        []int        // every card drawn, in order
+	Players      []PlayerRecord
+}
+
+type PlayerRecord struct {
+	Name     string
+	Strategy string // strategy name, or manual
+	Moves    []Move // one placement per card that fit, in card order
+}
+
+// the table so far as a record, only complete turns can be recorded
+func (table *Table) Record() (*TableRecord, error) {
+	if table.card >= 0 {
+		return nil, fmt.Errorf("num:%v is still being placed by %v", table.card, table.waitingNames())
+	}
+	rules := table.rules
+	rec := &TableRecord{Rules: rules.Name, R: table.R, C: table.C, Deck: table.deck, Shapes: rules.Shapes, Scoring: rules.Scoring,
+		Orientations: rules.Orientations, Support: rules.Support, Adjacency: rules.Adjacency, Cards: append([]int{}, table.drawn...)}
+	for _, p := range table.Players {
+		rec.Players = append(rec.Players, PlayerRecord{Name: p.Name, Strategy: p.StrategyName(), Moves: p.Board.History()})
+	}
+	return rec, nil
+}
+
+func (table *Table) Save(path string) error {
+	rec, err := table.Record()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// a saved table played back, engine players get their strategy again from its name
+func LoadTable(path string, seed int64) (*Table, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rec TableRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return nil, fmt.Errorf("error reading table:%v: %v", path, err)
+	}
+	return rec.Replay(seed)
+}
+
+func (rec *TableRecord) Replay(seed int64) (*Table, error) {
+	names := make([]string, len(rec.Players))
+	strategies := make([]Strategy, len(rec.Players))
+	for i, p := range rec.Players {
+		names[i] = p.Name
+		if p.Strategy == Manual {
+			continue
+		}
+		s, err := StrategyByName(p.Strategy, seed+int64(i))
+		if err != nil {
+			return nil, fmt.Errorf("player:%v: %v", p.Name, err)
+		}
+		strategies[i] = s
+	}
+	rules := rec.game(nil).rules()
+	table, err := NewTableWithRules(rules, names, strategies)
+	if err != nil {
+		return nil, err
+	}
+	for _, num := range rec.Cards {
+		if num < 0 || num >= len(table.deck) {
+			return nil, fmt.Errorf("num:%v is not a number", num)
+		}
+		table.seen[num]++
+		if table.seen[num] > table.deck[num] {
+			return nil, &SeenError{Num: num, Copies: table.deck[num]}
+		}
+	}
+	table.drawn = append(table.drawn, rec.Cards...)
+	for i, p := range rec.Players {
+		board, err := rec.game(p.Moves).Replay()
+		if err != nil {
+			return nil, fmt.Errorf("player:%v: %v", p.Name, err)
+		}
+		copy(board.seen, table.seen)
+		table.Players[i].Board = board
+	}
+	return table, nil
+}
+
+// the game of one player at the table
+func (rec *TableRecord) game(moves []Move) *GameRecord {
+	return &GameRecord{Rules: rec.Rules, R: rec.R, C: rec.C, SeenLimit: rec.SeenLimit, Deck: rec.Deck, Shapes: rec.Shapes,
+		Scoring: rec.Scoring, Orientations: rec.Orientations, Support: rec.Support, Adjacency: rec.Adjacency,
+		Cards: rec.Cards, Moves: moves}
+}
@@ -0,0 +1,158 @@
+package lib2
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestTableSharesDeck(t *testing.T) {
+	table, err := newTableRC(12, 12, UniformDeck(10, 1), []string{"ann", "bob", "cyd"}, []Strategy{nil, GreedyStrategy{}, LookaheadStrategy{Steps: 1}})
+	if err != nil {
+		t.Fatalf("err making table: %v", err)
+	}
+	if err := table.Draw(5); err != nil {
+		t.Fatalf("err drawing: %v", err)
+	}
+	if waiting := table.Waiting(); len(waiting) != 1 || waiting[0].Name != "ann" {
+		t.Fatalf("waiting: want ann != got:%v", table.waitingNames())
+	}
+	if err := table.Draw(9); err == nil {
+		t.Fatalf("draw should fail while ann still has to place 5")
+	}
+	if err := table.Place("bob", 0, 0, 0); err == nil {
+		t.Fatalf("bob plays by engine and shouldn't be able to place")
+	}
+	if err := table.Place("ann", 0, 0, 0); err != nil {
+		t.Fatalf("err placing: %v", err)
+	}
+	if _, ok := table.Card(); ok {
+		t.Fatalf("turn should be over once everyone placed")
+	}
+	// 5 is used up for every player, whoever placed it
+	if err := table.Draw(5); err == nil {
+		t.Fatalf("draw should fail on a card the shared deck has run out of")
+	}
+	for _, p := range table.Players {
+		if p.Board.Remaining()[5] != 0 {
+			t.Fatalf("player:%v remaining: %v", p.Name, p.Board.Remaining())
+		}
+	}
+	table.Draw(9)
+	moves := table.Players[0].Board.LegalMoves(9)
+	if err := table.Place("ann", moves[0].Row, moves[0].Col, moves[0].Orient); err != nil {
+		t.Fatalf("err placing: %v", err)
+	}
+	standings := table.Standings()
+	for i := 1; i < len(standings); i++ {
+		if standings[i-1].Board.Score() < standings[i].Board.Score() {
+			t.Fatalf("standings out of order")
+		}
+	}
+}
+
+func TestTableSaveLoad(t *testing.T) {
+	table, _ := NewTable([]string{"ann", "bob"}, []Strategy{nil, GreedyStrategy{}})
+	for _, num := range []int{5, 9, 0, 8} {
+		table.Draw(num)
+		if len(table.Waiting()) > 0 {
+			m := table.Players[0].Board.LegalMoves(num)[0]
+			if err := table.Place("ann", m.Row, m.Col, m.Orient); err != nil {
+				t.Fatalf("err placing: %v", err)
+			}
+		}
+	}
+	path := filepath.Join(t.TempDir(), "table.json")
+	if err := table.Save(path); err != nil {
+		t.Fatalf("err saving: %v", err)
+	}
+	loaded, err := LoadTable(path, 1)
+	if err != nil {
+		t.Fatalf("err loading: %v", err)
+	}
+	for i, p := range table.Players {
+		q := loaded.Players[i]
+		if q.Name != p.Name || q.StrategyName() != p.StrategyName() || q.Board.Score() != p.Board.Score() {
+			t.Fatalf("player %v: want:%v %v %v != got:%v %v %v", i, p.Name, p.StrategyName(), p.Board.Score(), q.Name, q.StrategyName(), q.Board.Score())
+		}
+		if q.Board.RenderIso() != p.Board.RenderIso() {
+			t.Fatalf("player:%v board differs after loading", p.Name)
+		}
+	}
+	table.Draw(7)
+	if len(table.Waiting()) > 0 {
+		if err := table.Save(path); err == nil {
+			t.Fatalf("save should fail in the middle of a turn")
+		}
+	}
+}
+
+func TestTableGameOver(t *testing.T) {
+	table, err := newTableRC(12, 12, Deck{1, 0, 0, 0, 0, 0, 0, 0, 1, 0}, []string{"ann", "bob"}, []Strategy{nil, GreedyStrategy{}})
+	if err != nil {
+		t.Fatalf("err making table: %v", err)
+	}
+	for _, num := range []int{8, 0} {
+		if table.GameOver() {
+			t.Fatalf("game over with num:%v still to draw", num)
+		}
+		table.Draw(num)
+		if table.GameOver() {
+			t.Fatalf("game over while ann places num:%v", num)
+		}
+		m := table.Players[0].Board.LegalMoves(num)[0]
+		if err := table.Place("ann", m.Row, m.Col, m.Orient); err != nil {
+			t.Fatalf("err placing: %v", err)
+		}
+	}
+	if !table.GameOver() {
+		t.Fatalf("every card has been placed, remaining: %v", table.Remaining())
+	}
+}
+
+// the rules reach every board, manual players can turn pieces like the engine
+func TestTableRules(t *testing.T) {
+	rules, err := RulesByName("house")
+	if err != nil {
+		t.Fatalf("err: %v", err)
+	}
+	rules.R, rules.C = 10, 10
+	table, err := NewTableWithRules(rules, []string{"ann", "bob"}, []Strategy{nil, GreedyStrategy{}})
+	if err != nil {
+		t.Fatalf("err making table: %v", err)
+	}
+	for _, num := range []int{5, 9, 7} {
+		table.Draw(num)
+		turned := Move{}
+		for _, m := range table.Players[0].Board.LegalMoves(num) {
+			if m.Orient != 0 {
+				turned = m
+			}
+		}
+		if turned.Orient == 0 {
+			t.Fatalf("num:%v can't be turned under %v", num, rules.Name)
+		}
+		if err := table.Place("ann", turned.Row, turned.Col, turned.Orient); err != nil {
+			t.Fatalf("err placing %+v: %v", turned, err)
+		}
+	}
+	for _, p := range table.Players {
+		if p.Board.R != 10 || p.Board.Orientations() != RotateFlip {
+			t.Fatalf("player:%v plays %v", p.Name, p.Board.Rules())
+		}
+	}
+	path := filepath.Join(t.TempDir(), "table.json")
+	if err := table.Save(path); err != nil {
+		t.Fatalf("err saving: %v", err)
+	}
+	loaded, err := LoadTable(path, 1)
+	if err != nil {
+		t.Fatalf("err loading: %v", err)
+	}
+	if got, want := loaded.Rules().String(), table.Rules().String(); got != want {
+		t.Fatalf("rules: want:%v != got:%v", want, got)
+	}
+	if got, want := loaded.Players[0].Board.History(), table.Players[0].Board.History(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("moves: want:%v != got:%v", want, got)
+	}
+}
@@ -0,0 +1,255 @@
+package lib2
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+)
+
+/*
+
+	tournament = every strategy plays the exact same shuffled decks, so two strategies
+				 can be compared deck by deck instead of only by their averages
+	pair = the score differences of two strategies over the same decks
+	rating = elo rating kept across tournaments in a local json file, every deck counts
+			 as one game between every pair of strategies, the higher score wins
+
+*/
+
+type TournamentConfig struct {
+	Games      int
+	Seed       int64
+	Strategies []string
+	R          int
+	C          int
+	SeenLimit  int
+	Weights    *Weights // evaluation at the search horizon, nil to skip
+}
+
+func DefaultTournamentConfig() TournamentConfig {
+	return TournamentConfig{
+		Games:      30,
+		Seed:       1,
+		Strategies: []string{"greedy", "lookahead:2", "expectimax:2"},
+		R:          12,
+		C:          12,
+		SeenLimit:  2,
+	}
+}
+
+type TournamentResult struct {
+	Names  []string
+	Seeds  []int64
+	Scores [][]int // Scores[strategy][game]
+}
+
+type PairResult struct {
+	A     string
+	B     string
+	Mean  float64 // mean of A-B over the same decks
+	Low   float64 // 95% confidence interval of the mean
+	High  float64
+	AWins int
+	BWins int
+	Draws int
+	Decks int
+}
+
+func RunTournament(cfg TournamentConfig) (*TournamentResult, error) {
+	if len(cfg.Strategies) < 2 {
+		return nil, fmt.Errorf("tournament needs at least 2 strategies, got:%v", len(cfg.Strategies))
+	}
+	res := &TournamentResult{
+		Names:  make([]string, len(cfg.Strategies)),
+		Seeds:  make([]int64, cfg.Games),
+		Scores: make([][]int, len(cfg.Strategies)),
+	}
+	// ratings are kept by name, two entrants with one name would share a rating
+	entrants := map[string]bool{}
+	for i, spec := range cfg.Strategies {
+		strategy, err := StrategyByName(spec, cfg.Seed)
+		if err != nil {
+			return nil, err
+		}
+		if entrants[strategy.Name()] {
+			return nil, fmt.Errorf("strategy:%v entered twice", strategy.Name())
+		}
+		entrants[strategy.Name()] = true
+		res.Names[i] = strategy.Name()
+	}
+	for g := 0; g < cfg.Games; g++ {
+		seed := cfg.Seed + int64(g)
+		res.Seeds[g] = seed
+		cards := ShuffledDeck(rand.New(rand.NewSource(seed)), cfg.SeenLimit)
+		for i, spec := range cfg.Strategies {
+			strategy, err := StrategyByName(spec, seed)
+			if err != nil {
+				return nil, err
+			}
+			board := newBoardRC(cfg.R, cfg.C, cfg.SeenLimit)
+			board.SetWeights(cfg.Weights)
+			game := PlayGame(board, cards, strategy)
+			res.Scores[i] = append(res.Scores[i], game.Score)
+		}
+	}
+	return res, nil
+}
+
+func (res *TournamentResult) Mean(i int) float64 {
+	scores := make([]float64, len(res.Scores[i]))
+	for g, s := range res.Scores[i] {
+		scores[g] = float64(s)
+	}
+	mean, _ := meanStdDev(scores)
+	return mean
+}
+
+// paired comparison of strategy a against strategy b
+func (res *TournamentResult) Pair(a, b int) PairResult {
+	pair := PairResult{A: res.Names[a], B: res.Names[b], Decks: len(res.Seeds)}
+	diffs := make([]float64, len(res.Seeds))
+	for g := range res.Seeds {
+		diff := res.Scores[a][g] - res.Scores[b][g]
+		diffs[g] = float64(diff)
+		switch {
+		case diff > 0:
+			pair.AWins++
+		case diff < 0:
+			pair.BWins++
+		default:
+			pair.Draws++
+		}
+	}
+	mean, sd := meanStdDev(diffs)
+	// normal approximation, good enough once there are a few dozen decks
+	half := 0.0
+	if len(diffs) > 1 {
+		half = 1.96 * sd / math.Sqrt(float64(len(diffs)))
+	}
+	pair.Mean, pair.Low, pair.High = mean, mean-half, mean+half
+	return pair
+}
+
+// every pair of strategies, in the order they were configured
+func (res *TournamentResult) Pairs() []PairResult {
+	pairs := []PairResult{}
+	for a := range res.Names {
+		for b := a + 1; b < len(res.Names); b++ {
+			pairs = append(pairs, res.Pair(a, b))
+		}
+	}
+	return pairs
+}
+
+func (res *TournamentResult) Print(w io.Writer) {
+	fmt.Fprintf(w, "decks: %v\n", len(res.Seeds))
+	for i, name := range res.Names {
+		fmt.Fprintf(w, "  %-16v mean %.2f\n", name, res.Mean(i))
+	}
+	fmt.Fprintln(w, "paired differences (95% confidence):")
+	for _, p := range res.Pairs() {
+		verdict := "no clear difference"
+		if p.Low > 0 {
+			verdict = p.A + " is better"
+		} else if p.High < 0 {
+			verdict = p.B + " is better"
+		}
+		fmt.Fprintf(w, "  %v - %v: %+.2f [%+.2f, %+.2f]  wins %v/%v draws %v  %v\n",
+			p.A, p.B, p.Mean, p.Low, p.High, p.AWins, p.BWins, p.Draws, verdict)
+	}
+}
+
+const eloK = 16
+const eloStart = 1500
+
+type Rating struct {
+	Rating float64
+	Games  int
+	Wins   int
+	Losses int
+	Draws  int
+}
+
+type Ratings map[string]*Rating
+
+// a missing file is an empty table
+func LoadRatings(path string) (Ratings, error) {
+	ratings := Ratings{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ratings, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &ratings); err != nil {
+		return nil, fmt.Errorf("error reading ratings:%v: %v", path, err)
+	}
+	return ratings, nil
+}
+
+func (ratings Ratings) Save(path string) error {
+	data, err := json.MarshalIndent(ratings, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func (ratings Ratings) get(name string) *Rating {
+	if ratings[name] == nil {
+		ratings[name] = &Rating{Rating: eloStart}
+	}
+	return ratings[name]
+}
+
+// plays out every deck of res as one game between every pair of strategies
+func (ratings Ratings) Update(res *TournamentResult) {
+	for g := range res.Seeds {
+		for a := range res.Names {
+			for b := a + 1; b < len(res.Names); b++ {
+				ra, rb := ratings.get(res.Names[a]), ratings.get(res.Names[b])
+				outcome := 0.5
+				switch sa, sb := res.Scores[a][g], res.Scores[b][g]; {
+				case sa > sb:
+					outcome = 1
+					ra.Wins++
+					rb.Losses++
+				case sa < sb:
+					outcome = 0
+					ra.Losses++
+					rb.Wins++
+				default:
+					ra.Draws++
+					rb.Draws++
+				}
+				expected := 1 / (1 + math.Pow(10, (rb.Rating-ra.Rating)/400))
+				ra.Rating += eloK * (outcome - expected)
+				rb.Rating -= eloK * (outcome - expected)
+				ra.Games++
+				rb.Games++
+			}
+		}
+	}
+}
+
+func (ratings Ratings) Print(w io.Writer) {
+	names := make([]string, 0, len(ratings))
+	for name := range ratings {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return ratings[names[i]].Rating > ratings[names[j]].Rating
+	})
+	fmt.Fprintln(w, "ratings:")
+	for _, name := range names {
+		r := ratings[name]
+		fmt.Fprintf(w, "  %-16v %7.1f  games %v  w/l/d %v/%v/%v\n", name, r.Rating, r.Games, r.Wins, r.Losses, r.Draws)
+	}
+}
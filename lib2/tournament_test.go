@@ -0,0 +1,80 @@
+package lib2
+
+import (
+	"bytes"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunTournament(t *testing.T) {
+	cfg := TournamentConfig{Games: 4, Seed: 3, Strategies: []string{"greedy", "random"}, R: 12, C: 12, SeenLimit: 1}
+	res, err := RunTournament(cfg)
+	if err != nil {
+		t.Fatalf("err running tournament: %v", err)
+	}
+	if len(res.Scores) != 2 || len(res.Scores[0]) != cfg.Games {
+		t.Fatalf("scores: want 2x%v != got:%v", cfg.Games, res.Scores)
+	}
+	pair := res.Pair(0, 1)
+	if pair.AWins+pair.BWins+pair.Draws != cfg.Games {
+		t.Fatalf("pair outcomes don't add up: %+v", pair)
+	}
+	if pair.Low > pair.Mean || pair.Mean > pair.High {
+		t.Fatalf("mean:%v not within [%v, %v]", pair.Mean, pair.Low, pair.High)
+	}
+	var buf bytes.Buffer
+	res.Print(&buf)
+	if _, err := RunTournament(TournamentConfig{Games: 1, Strategies: []string{"greedy"}}); err == nil {
+		t.Fatalf("a single strategy should not make a tournament")
+	}
+	// lookahead is lookahead:2, rated under the same name
+	for _, specs := range [][]string{{"greedy", "greedy"}, {"lookahead", "random", "lookahead:2"}} {
+		if _, err := RunTournament(TournamentConfig{Games: 1, Strategies: specs, R: 12, C: 12, SeenLimit: 1}); err == nil {
+			t.Fatalf("strategies:%v entered twice should be an error", specs)
+		}
+	}
+}
+
+func TestPairSameDecks(t *testing.T) {
+	res := &TournamentResult{
+		Names:  []string{"a", "b"},
+		Seeds:  []int64{1, 2, 3, 4},
+		Scores: [][]int{{10, 20, 30, 40}, {8, 18, 28, 38}},
+	}
+	// b is always exactly 2 behind, so the interval has no width
+	pair := res.Pair(0, 1)
+	if pair.Mean != 2 || pair.Low != 2 || pair.High != 2 || pair.AWins != 4 {
+		t.Fatalf("pair: got %+v", pair)
+	}
+}
+
+func TestRatings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ratings.json")
+	ratings, err := LoadRatings(path)
+	if err != nil {
+		t.Fatalf("missing ratings file should be empty: %v", err)
+	}
+	res := &TournamentResult{
+		Names:  []string{"a", "b"},
+		Seeds:  []int64{1, 2, 3},
+		Scores: [][]int{{10, 20, 30}, {8, 20, 28}},
+	}
+	ratings.Update(res)
+	if ratings["a"].Rating <= ratings["b"].Rating {
+		t.Fatalf("a won more, rating should be higher: %v <= %v", ratings["a"].Rating, ratings["b"].Rating)
+	}
+	if sum := ratings["a"].Rating + ratings["b"].Rating; math.Abs(sum-2*eloStart) > 1e-9 {
+		t.Fatalf("elo should be zero sum: %v", sum)
+	}
+	if err := ratings.Save(path); err != nil {
+		t.Fatalf("err saving ratings: %v", err)
+	}
+	loaded, err := LoadRatings(path)
+	if err != nil {
+		t.Fatalf("err loading ratings: %v", err)
+	}
+	if *loaded["a"] != *ratings["a"] || loaded["b"].Draws != 1 {
+		t.Fatalf("loaded: want:%+v != got:%+v", ratings["a"], loaded["a"])
+	}
+}
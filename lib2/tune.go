@@ -0,0 +1,236 @@
+package lib2
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math/rand"
+	"os"
+	"sort"
+)
+
+/*
+
+	tuning = a genetic search for evaluation weights through self-play
+	fitness = mean final score of a strategy using the weights, always on the same decks
+			  so that two sets of weights are compared on equal terms
+	generation = every member of the population is scored, the best half survives as is
+				 and the other half is refilled with crossovers of survivors plus gaussian noise
+	checkpoint = the whole state after every generation, a run picks up where it left off
+				 since every generation draws from its own seed
+
+*/
+
+type TuneConfig struct {
+	Generations int
+	Population  int
+	Sigma       float64 // size of the mutations relative to each weight, shrinks by Decay every generation
+	Decay       float64
+	Games       int // decks every member plays
+	Seed        int64
+	Strategy    string
+	R           int
+	C           int
+	SeenLimit   int
+	Out         string // best weights so far, written after every generation
+	Checkpoint  string // "" to not checkpoint
+}
+
+func DefaultTuneConfig() TuneConfig {
+	return TuneConfig{
+		Generations: 10,
+		Population:  8,
+		Sigma:       0.3,
+		Decay:       0.9,
+		Games:       10,
+		Seed:        1,
+		Strategy:    "lookahead:1",
+		R:           12,
+		C:           12,
+		SeenLimit:   2,
+		Out:         "weights.json",
+		Checkpoint:  "tune_checkpoint.json",
+	}
+}
+
+type TuneState struct {
+	// the config the population was bred with, a run only resumes with the same
+	Config     TuneConfig
+	Generation int // generations finished
+	Population []Weights
+	Fitness    []float64
+	Best       Weights
+	BestScore  float64
+	History    []float64 // best fitness of every generation
+}
+
+func (w Weights) vector() []float64 {
+	return []float64{w.Plateau, w.Connected, w.Reach, w.Anchors, w.Holes}
+}
+
+func weightsFromVector(v []float64) Weights {
+	return Weights{Plateau: v[0], Connected: v[1], Reach: v[2], Anchors: v[3], Holes: v[4]}
+}
+
+// mean score of playing cfg.Games fixed decks with w
+func (cfg TuneConfig) fitness(w Weights) (float64, error) {
+	results, err := Simulate(SimConfig{
+		Games:     cfg.Games,
+		Seed:      cfg.Seed,
+		Strategy:  cfg.Strategy,
+		R:         cfg.R,
+		C:         cfg.C,
+		SeenLimit: cfg.SeenLimit,
+		Weights:   &w,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return Summarize(results).Mean, nil
+}
+
+func LoadTuneState(path string) (*TuneState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var state TuneState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("error reading checkpoint:%v: %v", path, err)
+	}
+	return &state, nil
+}
+
+func (state *TuneState) Save(path string) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// runs the genetic search, resuming from cfg.Checkpoint if it exists
+func Tune(cfg TuneConfig, progress io.Writer) (*TuneState, error) {
+	if cfg.Population < 2 {
+		return nil, fmt.Errorf("population needs at least 2 members, got:%v", cfg.Population)
+	}
+	state, err := LoadTuneState(cfg.Checkpoint)
+	if errors.Is(err, fs.ErrNotExist) || cfg.Checkpoint == "" {
+		state = newTuneState(cfg)
+	} else if err != nil {
+		return nil, err
+	} else if state.Config != cfg.search() {
+		return nil, fmt.Errorf("checkpoint:%v was made with %+v, not %+v", cfg.Checkpoint, state.Config, cfg.search())
+	} else {
+		fmt.Fprintf(progress, "resuming after generation %v, best %.2f\n", state.Generation, state.BestScore)
+	}
+	for state.Generation < cfg.Generations {
+		gen := state.Generation
+		// score whoever hasn't been scored yet, survivors keep their fitness
+		for i, w := range state.Population {
+			if i < len(state.Fitness) {
+				continue
+			}
+			fit, err := cfg.fitness(w)
+			if err != nil {
+				return nil, err
+			}
+			state.Fitness = append(state.Fitness, fit)
+		}
+		order := make([]int, len(state.Population))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool {
+			return state.Fitness[order[a]] > state.Fitness[order[b]]
+		})
+		if best := order[0]; len(state.History) == 0 || state.Fitness[best] > state.BestScore {
+			state.Best = state.Population[best]
+			state.BestScore = state.Fitness[best]
+		}
+		state.History = append(state.History, state.Fitness[order[0]])
+		fmt.Fprintf(progress, "generation %v: best %.2f  overall %.2f  %+v\n", gen, state.Fitness[order[0]], state.BestScore, state.Best)
+
+		state.breed(cfg, order)
+		state.Generation++
+		if cfg.Out != "" {
+			if err := state.Best.Save(cfg.Out); err != nil {
+				return nil, err
+			}
+		}
+		if cfg.Checkpoint != "" {
+			if err := state.Save(cfg.Checkpoint); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return state, nil
+}
+
+// cfg without what a resumed run may change, how long it runs and where it writes
+func (cfg TuneConfig) search() TuneConfig {
+	cfg.Generations, cfg.Out, cfg.Checkpoint = 0, "", ""
+	return cfg
+}
+
+// first generation: the default weights and mutations of them
+func newTuneState(cfg TuneConfig) *TuneState {
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	state := &TuneState{Config: cfg.search(), Population: []Weights{DefaultWeights()}}
+	for len(state.Population) < cfg.Population {
+		state.Population = append(state.Population, mutate(rng, DefaultWeights().vector(), cfg.Sigma))
+	}
+	return state
+}
+
+// keeps the best half of the population ranked by order and replaces the rest
+func (state *TuneState) breed(cfg TuneConfig, order []int) {
+	rng := rand.New(rand.NewSource(cfg.Seed + int64(state.Generation) + 1))
+	sigma := cfg.Sigma
+	for i := 0; i < state.Generation; i++ {
+		sigma *= cfg.Decay
+	}
+	keep := (len(order) + 1) / 2
+	population := make([]Weights, 0, len(order))
+	fitness := make([]float64, 0, len(order))
+	for _, i := range order[:keep] {
+		population = append(population, state.Population[i])
+		fitness = append(fitness, state.Fitness[i])
+	}
+	for len(population) < len(order) {
+		a := population[rng.Intn(keep)].vector()
+		b := population[rng.Intn(keep)].vector()
+		child := make([]float64, len(a))
+		for k := range child {
+			child[k] = a[k]
+			if rng.Intn(2) == 0 {
+				child[k] = b[k]
+			}
+		}
+		population = append(population, mutate(rng, child, sigma))
+	}
+	state.Population = population
+	state.Fitness = fitness
+}
+
+// gaussian noise relative to each weight, so small and large weights both move
+func mutate(rng *rand.Rand, v []float64, sigma float64) Weights {
+	child := make([]float64, len(v))
+	for k, x := range v {
+		scale := abs(x)
+		if scale < 0.01 {
+			scale = 0.01
+		}
+		child[k] = x + rng.NormFloat64()*sigma*scale
+	}
+	return weightsFromVector(child)
+}
+
+func abs(x float64) float64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
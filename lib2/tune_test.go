@@ -0,0 +1,93 @@
+package lib2
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestTuneResumes(t *testing.T) {
+	dir := t.TempDir()
+	cfg := DefaultTuneConfig()
+	cfg.Generations = 1
+	cfg.Population = 4
+	cfg.Games = 2
+	cfg.Out = filepath.Join(dir, "weights.json")
+	cfg.Checkpoint = filepath.Join(dir, "checkpoint.json")
+	var progress bytes.Buffer
+	first, err := Tune(cfg, &progress)
+	if err != nil {
+		t.Fatalf("err tuning: %v", err)
+	}
+	if first.Generation != 1 || len(first.History) != 1 {
+		t.Fatalf("generations: want:1 != got:%v", first.Generation)
+	}
+	if _, err := LoadWeights(cfg.Out); err != nil {
+		t.Fatalf("best weights should be written: %v", err)
+	}
+	// a longer run picks up from the checkpoint instead of starting over
+	cfg.Generations = 2
+	second, err := Tune(cfg, &progress)
+	if err != nil {
+		t.Fatalf("err tuning: %v", err)
+	}
+	if second.Generation != 2 || len(second.History) != 2 || second.History[0] != first.History[0] {
+		t.Fatalf("history should continue: %v then %v", first.History, second.History)
+	}
+	if second.BestScore < first.BestScore {
+		t.Fatalf("best score can't get worse: %v < %v", second.BestScore, first.BestScore)
+	}
+	if !bytes.Contains(progress.Bytes(), []byte("resuming after generation 1")) {
+		t.Fatalf("second run should resume:\n%v", progress.String())
+	}
+	// the lookahead plays by the weights, so the members don't all score the same
+	if second.Best == DefaultWeights() {
+		t.Fatalf("tuning kept the seed weights: %+v", second.Best)
+	}
+	seed, err := cfg.fitness(DefaultWeights())
+	if err != nil {
+		t.Fatalf("err scoring the seed weights: %v", err)
+	}
+	if second.BestScore <= seed {
+		t.Fatalf("best score:%v should beat the seed weights' %v", second.BestScore, seed)
+	}
+	// a checkpoint bred some other way can't be resumed
+	cfg.Generations = 3
+	for name, change := range map[string]func(cfg *TuneConfig){
+		"seed":       func(cfg *TuneConfig) { cfg.Seed++ },
+		"games":      func(cfg *TuneConfig) { cfg.Games++ },
+		"strategy":   func(cfg *TuneConfig) { cfg.Strategy = "lookahead:2" },
+		"population": func(cfg *TuneConfig) { cfg.Population++ },
+		"sigma":      func(cfg *TuneConfig) { cfg.Sigma *= 2 },
+		"decay":      func(cfg *TuneConfig) { cfg.Decay = 1 },
+		"rows":       func(cfg *TuneConfig) { cfg.R++ },
+		"cols":       func(cfg *TuneConfig) { cfg.C++ },
+		"seen limit": func(cfg *TuneConfig) { cfg.SeenLimit++ },
+	} {
+		other := cfg
+		change(&other)
+		if _, err := Tune(other, &progress); err == nil {
+			t.Fatalf("checkpoint with another %v should be an error", name)
+		}
+	}
+	// where the weights are written isn't part of the search
+	cfg.Out = filepath.Join(dir, "other.json")
+	if _, err := Tune(cfg, &progress); err != nil {
+		t.Fatalf("err resuming with another -out: %v", err)
+	}
+}
+
+func TestBreedKeepsBestHalf(t *testing.T) {
+	cfg := DefaultTuneConfig()
+	state := &TuneState{
+		Population: []Weights{{Reach: 1}, {Reach: 2}, {Reach: 3}, {Reach: 4}},
+		Fitness:    []float64{10, 40, 30, 20},
+	}
+	state.breed(cfg, []int{1, 2, 3, 0})
+	if len(state.Population) != 4 || len(state.Fitness) != 2 {
+		t.Fatalf("population:%v fitness:%v", len(state.Population), len(state.Fitness))
+	}
+	if state.Population[0].Reach != 2 || state.Population[1].Reach != 3 {
+		t.Fatalf("survivors: got %+v", state.Population[:2])
+	}
+}
@@ -0,0 +1,133 @@
+package lib2
+
+import (
+	"fmt"
+	"math"
+)
+
+/*
+
+	validate = the board checked against itself, everything the fast paths keep up to
+			   date by hand has to agree with what was placed
+				flat		every cell is the level of the top tile in the layers
+				bounds		the bounding box of flat and every layer is as tight as the tiles
+				seen		every number seen as often as it was drawn, never more than the deck
+				history		the history played back on a new board with the rules makes the
+							same layers, so every placement was legal when it was made
+	debug builds (go build -tags nmbr9debug) validate after every move and panic on the
+	first one that leaves the board inconsistent
+
+*/
+
+// error describing the first way the board disagrees with itself, nil if it doesn't
+func (board *Board) Validate() error {
+	if err := board.validateFlat(); err != nil {
+		return err
+	}
+	if err := board.validateBounds(); err != nil {
+		return err
+	}
+	if err := board.validateSeen(); err != nil {
+		return err
+	}
+	return board.validateHistory()
+}
+
+func (board *Board) validateFlat() error {
+	for r := 0; r < board.R; r++ {
+		for c := 0; c < board.C; c++ {
+			top := EMPTY
+			for level, layer := range board.layers {
+				if layer.Get(r, c) != EMPTY {
+					top = int8(level)
+				}
+			}
+			if got := board.flat.Get(r, c); got != top {
+				return fmt.Errorf("flat:(%v,%v) is level %v but the top tile there is on level %v", r, c, got, top)
+			}
+		}
+	}
+	return nil
+}
+
+func (board *Board) validateBounds() error {
+	if err := validateBounds(board.flat); err != nil {
+		return fmt.Errorf("flat: %v", err)
+	}
+	for level, layer := range board.layers {
+		if layer.BB_TL_R > layer.BB_BR_R {
+			return fmt.Errorf("level:%v has no tiles", level)
+		}
+		if err := validateBounds(layer); err != nil {
+			return fmt.Errorf("level:%v: %v", level, err)
+		}
+	}
+	return nil
+}
+
+// error if the bounding box of layer isn't exactly around its tiles
+func validateBounds(layer *Layer) error {
+	tlR, tlC, brR, brC := math.MaxInt, math.MaxInt, math.MinInt, math.MinInt
+	for r := 0; r < layer.R; r++ {
+		for c := 0; c < layer.C; c++ {
+			if layer.Get(r, c) != EMPTY {
+				tlR, tlC, brR, brC = min(tlR, r), min(tlC, c), max(brR, r), max(brC, c)
+			}
+		}
+	}
+	if tlR != layer.BB_TL_R || tlC != layer.BB_TL_C || brR != layer.BB_BR_R || brC != layer.BB_BR_C {
+		return fmt.Errorf("bounding box (%v,%v)-(%v,%v) but the tiles are in (%v,%v)-(%v,%v)",
+			layer.BB_TL_R, layer.BB_TL_C, layer.BB_BR_R, layer.BB_BR_C, tlR, tlC, brR, brC)
+	}
+	return nil
+}
+
+func (board *Board) validateSeen() error {
+	drawn := make([]int, len(board.seen))
+	for _, num := range board.drawn {
+		if num < 0 || num >= len(drawn) {
+			return fmt.Errorf("num:%v drawn is not a number", num)
+		}
+		drawn[num]++
+	}
+	for num, n := range board.seen {
+		if n != drawn[num] {
+			return fmt.Errorf("num:%v seen %v times but drawn %v times", num, n, drawn[num])
+		}
+		if n > board.copies(num) {
+			return fmt.Errorf("num:%v seen %v times, more than the %v in the deck", num, n, board.copies(num))
+		}
+	}
+	return nil
+}
+
+// plays the record of the board back and compares the layers it makes
+func (board *Board) validateHistory() error {
+	replayed, err := board.Record().replay()
+	if err != nil {
+		return fmt.Errorf("history: %v", err)
+	}
+	if len(replayed.layers) != len(board.layers) {
+		return fmt.Errorf("history makes %v levels, the board has %v", len(replayed.layers), len(board.layers))
+	}
+	for level, layer := range board.layers {
+		for r := 0; r < board.R; r++ {
+			for c := 0; c < board.C; c++ {
+				if want, got := replayed.layers[level].Get(r, c), layer.Get(r, c); want != got {
+					return fmt.Errorf("level:%v (%v,%v) holds %v but the history puts %v there", level, r, c, got, want)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// panics in debug builds when the board is inconsistent
+func (board *Board) debugValidate() {
+	if !debug {
+		return
+	}
+	if err := board.Validate(); err != nil {
+		panic(fmt.Sprintf("board invalid after move %v: %v", len(board.history), err))
+	}
+}
@@ -0,0 +1,107 @@
+package lib2
+
+import (
+	"strings"
+	"testing"
+)
+
+// a game with a stacked level and level 0 pieces placed after it
+func validGame(t *testing.T) *Board {
+	board := NewBoard()
+	for _, num := range []int{5, 9, 0, 8, 1, 7, 6, 2, 3, 4} {
+		if err, _ := board.ApplyBestMove(num, 1); err != nil {
+			t.Fatalf("err applying best move: %v", err)
+		}
+		if err := board.Validate(); err != nil {
+			t.Fatalf("num:%v: %v", num, err)
+		}
+	}
+	if len(board.layers) < 2 {
+		t.Fatalf("nothing was stacked")
+	}
+	return board
+}
+
+func TestValidateStackedThenFlat(t *testing.T) {
+	board := NewBoard()
+	// the 8 goes on top of the 9 and the 0, the 7 next to them on level 0
+	for _, m := range []Move{{Num: 5}, {Num: 9, Row: 0, Col: 3}, {Num: 0, Row: 0, Col: 0}, {Num: 8, Row: 0, Col: 2}, {Num: 7, Row: 0, Col: 6}} {
+		if err := board.ApplyMove(m); err != nil {
+			t.Fatalf("err applying %+v: %v", m, err)
+		}
+	}
+	if board.history[3].Level != 1 || board.history[4].Level != 0 {
+		t.Fatalf("history: %v", board.history)
+	}
+	// placing the 7 used to flatten all of level 0 over the 8
+	if err := board.Validate(); err != nil {
+		t.Fatalf("%v", err)
+	}
+}
+
+func TestValidateFindsCorruption(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(board *Board)
+		want    string
+	}{
+		{"flat", func(board *Board) {
+			r, c := board.flat.BB_TL_R, board.flat.BB_TL_C
+			for board.flat.Get(r, c) == EMPTY {
+				c++
+			}
+			board.flat.cells[r*board.C+c]++
+		}, "flat:"},
+		{"bounds", func(board *Board) { board.layers[0].BB_TL_R-- }, "bounding box"},
+		{"empty level", func(board *Board) { board.layers = append(board.layers, makeLayerRC(board.R, board.C)) }, "no tiles"},
+		{"seen", func(board *Board) { board.seen[3]-- }, "seen"},
+		{"drawn", func(board *Board) { board.drawn = board.drawn[1:] }, "seen"},
+		{"level", func(board *Board) { board.history[len(board.history)-1].Level++ }, "history"},
+		{"move", func(board *Board) { board.history[2].Col++ }, "history"},
+		{"tile", func(board *Board) {
+			// a tile written straight into a layer, with flat kept in step
+			layer := board.layers[0]
+			r, c := layer.BB_TL_R, layer.BB_TL_C
+			for board.flat.Get(r, c) != EMPTY {
+				c++
+			}
+			layer.Set(r, c, 4)
+			board.flat.Set(r, c, 0)
+		}, "history puts"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			board := validGame(t)
+			test.corrupt(board)
+			err := board.Validate()
+			if err == nil {
+				t.Fatalf("corrupt board validated")
+			}
+			if !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("want %q in: %v", test.want, err)
+			}
+		})
+	}
+}
+
+func TestValidateAfterRules(t *testing.T) {
+	for _, name := range []string{"official", "beginner", "house"} {
+		rules, err := RulesByName(name)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		board, err := NewBoardWithRules(rules)
+		if err != nil {
+			t.Fatalf("err: %v", err)
+		}
+		for _, num := range []int{5, 9, 0, 8, 1, 7, 6, 2} {
+			board.ApplyBestMove(num, 1)
+		}
+		if err := board.Validate(); err != nil {
+			t.Fatalf("%v: %v", name, err)
+		}
+		if err := board.Clone().Validate(); err != nil {
+			t.Fatalf("%v clone: %v", name, err)
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"nmbr9/lib2"
+	"os"
+	"time"
 )
 
 /*
@@ -32,15 +35,79 @@ import (
 */
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "simulate":
+			runSimulate(os.Args[2:])
+			return
+		case "tournament":
+			runTournament(os.Args[2:])
+			return
+		case "tune":
+			runTune(os.Args[2:])
+			return
+		case "solve":
+			runSolve(os.Args[2:])
+			return
+		case "analyze":
+			runAnalyze(os.Args[2:])
+			return
+		case "coach":
+			runCoach(os.Args[2:])
+			return
+		case "table":
+			runTable(os.Args[2:])
+			return
+		case "challenge":
+			runChallenge(os.Args[2:])
+			return
+		case "diff":
+			runDiff(os.Args[2:])
+			return
+		case "heatmap":
+			runHeatmap(os.Args[2:])
+			return
+		}
+	}
+	iso := flag.Bool("iso", false, "draw the board as one isometric picture instead of one grid per level")
+	name := flag.String("strategy", "", "strategy that places the tiles: "+lib2.StrategyNames+" (default: lookahead, asks for steps)")
+	weightsPath := flag.String("weights", "", weightsUsage)
+	endgame := flag.Int("endgame", 0, endgameUsage)
+	save := flag.String("save", "", "write the game to this file after every card, for analyze")
+	high := flag.Int("high", 7, "lowest points per level the deck panel counts as a high card")
+	rules := addRuleFlags(flag.CommandLine)
+	flag.Parse()
+	weights, err := loadWeights(*weightsPath)
+	if err != nil {
+		fmt.Printf("error loading weights: %v\n", err)
+		return
+	}
 	// input := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
-	steps := 4
-	fmt.Printf("how many steps ahead (default:%v): ", steps)
-	_, err := fmt.Scanf("%d\n", &steps)
-	if err != nil && err.Error() != "unexpected newline" {
-		fmt.Printf("error scanning input: %v\n", err)
+	var strategy lib2.Strategy
+	if *name == "" {
+		steps := 4
+		fmt.Printf("how many steps ahead (default:%v): ", steps)
+		_, err := fmt.Scanf("%d\n", &steps)
+		if err != nil && err.Error() != "unexpected newline" {
+			fmt.Printf("error scanning input: %v\n", err)
+			return
+		}
+		strategy = lib2.LookaheadStrategy{Steps: steps}
+	} else {
+		strategy, err = lib2.StrategyByName(*name, time.Now().UnixNano())
+		if err != nil {
+			fmt.Printf("error picking strategy: %v\n", err)
+			return
+		}
+	}
+	board, err := rules.board()
+	if err != nil {
+		fmt.Printf("error setting up board: %v\n", err)
 		return
 	}
-	board := lib2.NewBoard()
+	board.SetWeights(weights)
+	board.SetEndgame(*endgame)
+	board.DeckStatus(*high).Print(os.Stdout)
 	var num int
 	for {
 		fmt.Print("enter a number: ")
@@ -49,13 +116,32 @@ func main() {
 			fmt.Printf("error scanning input: %v\n", err)
 			break
 		}
-		err, _ = board.ApplyBestMove(num, steps)
+		move, err := board.Play(strategy, num)
+		if *save != "" {
+			if err := board.SaveGame(*save); err != nil {
+				fmt.Printf("error saving game: %v\n", err)
+			}
+		}
 		if err != nil {
 			fmt.Printf("error applying best move: %v\n", err)
-			continue
 		} else {
-			board.PrintOverlays(false)
+			if *iso {
+				board.PrintIso()
+			} else {
+				board.PrintOverlays(false)
+			}
+			if mcts, ok := strategy.(*lib2.MCTSStrategy); ok {
+				mcts.PrintStats(os.Stdout)
+			}
+			if info := board.LastSearch(); info.Move == move {
+				fmt.Println(info)
+			}
 			// fmt.Printf("best move score: %v\n", score)
 		}
+		if board.GameOver() {
+			fmt.Printf("game over, final score %v\n", board.Score())
+			break
+		}
+		board.DeckStatus(*high).Print(os.Stdout)
 	}
 }
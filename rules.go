@@ -0,0 +1,79 @@
+package main
+
+import (
+	"flag"
+	"nmbr9/lib2"
+)
+
+// flags that pick the rules of a game, every flag left empty keeps what -rules says
+type ruleFlags struct {
+	rules     *string
+	shapes    *string
+	scoring   *string
+	deck      *string
+	orient    *string
+	support   *string
+	adjacency *string
+}
+
+func addRuleFlags(fs *flag.FlagSet) *ruleFlags {
+	return &ruleFlags{
+		rules:     fs.String("rules", "official", "rules to start from: "+lib2.RulesNames),
+		shapes:    fs.String("shapes", "", "play with the pieces of a shapes file (text, or json ending in .json) instead of the numbers 0-9"),
+		scoring:   fs.String("scoring", "", "how placements score: "+lib2.ScoringNames),
+		deck:      fs.String("deck", "", "comma separated copies of every number, e.g. 3,2,2,2,2,2,2,2,2,1"),
+		orient:    fs.String("orient", "", "how pieces may be turned over and around: "+lib2.OrientationNames),
+		support:   fs.String("support", "", "what a piece above the bottom has to lie on: "+lib2.SupportNames),
+		adjacency: fs.String("adjacency", "", "which levels a new piece has to touch a piece already on: "+lib2.AdjacencyNames),
+	}
+}
+
+// the rules the flags pick
+func (f *ruleFlags) Rules() (*lib2.Rules, error) {
+	rules, err := lib2.RulesByName(*f.rules)
+	if err != nil {
+		return nil, err
+	}
+	if *f.shapes != "" {
+		if rules.Shapes, err = lib2.LoadShapes(*f.shapes); err != nil {
+			return nil, err
+		}
+		// the copies come with the shapes
+		rules.Deck = nil
+	}
+	if *f.scoring != "" {
+		if rules.Scoring, err = lib2.ScoringByName(*f.scoring); err != nil {
+			return nil, err
+		}
+	}
+	if *f.deck != "" {
+		if rules.Deck, err = lib2.ParseDeck(*f.deck); err != nil {
+			return nil, err
+		}
+	}
+	if *f.orient != "" {
+		if rules.Orientations, err = lib2.OrientationsByName(*f.orient); err != nil {
+			return nil, err
+		}
+	}
+	if *f.support != "" {
+		if rules.Support, err = lib2.SupportByName(*f.support); err != nil {
+			return nil, err
+		}
+	}
+	if *f.adjacency != "" {
+		if rules.Adjacency, err = lib2.AdjacencyByName(*f.adjacency); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+// an empty board played by the rules the flags pick
+func (f *ruleFlags) board() (*lib2.Board, error) {
+	rules, err := f.Rules()
+	if err != nil {
+		return nil, err
+	}
+	return lib2.NewBoardWithRules(rules)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"nmbr9/lib2"
+	"os"
+)
+
+// nmbr9 simulate -games 100 -seed 1 -strategy lookahead:2 -csv games.csv
+func runSimulate(args []string) {
+	cfg := lib2.DefaultSimConfig()
+	fs := flag.NewFlagSet("simulate", flag.ExitOnError)
+	fs.IntVar(&cfg.Games, "games", cfg.Games, "number of complete games to play")
+	fs.Int64Var(&cfg.Seed, "seed", cfg.Seed, "seed of the first game, game i uses seed+i")
+	fs.StringVar(&cfg.Strategy, "strategy", cfg.Strategy, "strategy that places the tiles: "+lib2.StrategyNames)
+	fs.IntVar(&cfg.SeenLimit, "copies", cfg.SeenLimit, "copies of each number in the deck, unless the rules or -deck say otherwise")
+	bucket := fs.Int("bucket", 10, "width of a histogram bucket in points")
+	csvPath := fs.String("csv", "", "also write one row per game to this csv file")
+	weightsPath := fs.String("weights", "", weightsUsage)
+	fs.IntVar(&cfg.Endgame, "endgame", cfg.Endgame, endgameUsage)
+	rules := addRuleFlags(fs)
+	fs.Parse(args)
+	weights, err := loadWeights(*weightsPath)
+	if err != nil {
+		fmt.Printf("error loading weights: %v\n", err)
+		return
+	}
+	cfg.Weights = weights
+	if cfg.Rules, err = rules.Rules(); err != nil {
+		fmt.Printf("error picking rules: %v\n", err)
+		return
+	}
+	if cfg.Rules.Deck == nil && cfg.Rules.Shapes == nil {
+		cfg.Rules.Deck = lib2.UniformDeck(len(lib2.NUMBER), cfg.SeenLimit)
+	}
+
+	results, err := lib2.Simulate(cfg)
+	if err != nil {
+		fmt.Printf("error simulating: %v\n", err)
+		return
+	}
+	sum := lib2.Summarize(results)
+	sum.Print(os.Stdout)
+	fmt.Println()
+	lib2.PrintHistogram(os.Stdout, results, *bucket)
+	if *csvPath != "" {
+		f, err := os.Create(*csvPath)
+		if err != nil {
+			fmt.Printf("error creating csv: %v\n", err)
+			return
+		}
+		defer f.Close()
+		if err := lib2.WriteCSV(f, results); err != nil {
+			fmt.Printf("error writing csv: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"nmbr9/lib2"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// nmbr9 solve -cards 5,9,8,7,6
+func runSolve(args []string) {
+	fs := flag.NewFlagSet("solve", flag.ExitOnError)
+	cardList := fs.String("cards", "", "comma separated card order, e.g. 5,9,8,7")
+	maxNodes := fs.Int("max-nodes", 5000000, "stop and return the best line so far after this many positions, 0 for no limit")
+	fs.Parse(args)
+	cards, err := parseCards(*cardList)
+	if err != nil {
+		fmt.Printf("error reading cards: %v\n", err)
+		return
+	}
+	board := lib2.NewBoard()
+	sol, err := board.Solve(cards, lib2.SolveConfig{MaxNodes: *maxNodes})
+	if err != nil {
+		fmt.Printf("error solving: %v\n", err)
+		return
+	}
+	for _, m := range sol.Moves {
+		board.ApplyMove(m)
+	}
+	board.PrintIso()
+	sol.Print(os.Stdout)
+}
+
+func parseCards(list string) ([]int, error) {
+	if list == "" {
+		return nil, fmt.Errorf("no cards given")
+	}
+	parts := strings.Split(list, ",")
+	cards := make([]int, len(parts))
+	for i, part := range parts {
+		num, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("card:%q is not a number", part)
+		}
+		cards[i] = num
+	}
+	return cards, nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"nmbr9/lib2"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// nmbr9 table -players ann:manual,bob:lookahead:2,cyd:greedy -rules house
+// manual players place with "row col", or "row col o" to turn the number to orientation o
+func runTable(args []string) {
+	fs := flag.NewFlagSet("table", flag.ExitOnError)
+	players := fs.String("players", "you:manual,engine:lookahead:2", "comma separated name:strategy, strategy is manual or one of: "+lib2.StrategyNames)
+	load := fs.String("load", "", "carry on with a saved table, -players and the rules are ignored")
+	save := fs.String("save", "", "write the table to this file after every card")
+	endgame := fs.Int("endgame", 0, endgameUsage)
+	ruleFlags := addRuleFlags(fs)
+	fs.Parse(args)
+	seed := time.Now().UnixNano()
+	var table *lib2.Table
+	var err error
+	if *load != "" {
+		table, err = lib2.LoadTable(*load, seed)
+	} else {
+		var rules *lib2.Rules
+		if rules, err = ruleFlags.Rules(); err == nil {
+			table, err = newTable(rules, *players, seed)
+		}
+	}
+	if err != nil {
+		fmt.Printf("error setting up table: %v\n", err)
+		return
+	}
+	for _, p := range table.Players {
+		p.Board.SetEndgame(*endgame)
+	}
+	in := bufio.NewScanner(os.Stdin)
+	for !table.GameOver() {
+		fmt.Print("enter a number: ")
+		if !in.Scan() {
+			fmt.Println("table abandoned")
+			return
+		}
+		num, err := strconv.Atoi(strings.TrimSpace(in.Text()))
+		if err != nil {
+			fmt.Printf("error reading number: %v\n", err)
+			continue
+		}
+		if err := table.Draw(num); err != nil {
+			fmt.Printf("error drawing card: %v\n", err)
+			continue
+		}
+		for _, p := range table.Waiting() {
+			p.Board.PrintIso()
+			for {
+				fmt.Printf("%v, where does %v go (row col [o]): ", p.Name, num)
+				if !in.Scan() {
+					fmt.Println("table abandoned")
+					return
+				}
+				row, col, o, err := parsePlacement(in.Text())
+				if err != nil {
+					fmt.Printf("error reading placement: %v\n", err)
+					continue
+				}
+				if err := table.Place(p.Name, row, col, o); err != nil {
+					fmt.Printf("error placing card: %v\n", err)
+					continue
+				}
+				break
+			}
+		}
+		table.PrintViews(os.Stdout)
+		table.PrintScores(os.Stdout)
+		if *save != "" {
+			if err := table.Save(*save); err != nil {
+				fmt.Printf("error saving table: %v\n", err)
+			}
+		}
+	}
+	fmt.Println("game over, final scores")
+	table.PrintScores(os.Stdout)
+}
+
+// "row col" or "row col o"
+func parsePlacement(line string) (int, int, int, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 2 && len(fields) != 3 {
+		return 0, 0, 0, fmt.Errorf("want row col or row col o, got:%q", line)
+	}
+	nums := make([]int, 3)
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return 0, 0, 0, fmt.Errorf("%q is not a number", f)
+		}
+		nums[i] = n
+	}
+	return nums[0], nums[1], nums[2], nil
+}
+
+func newTable(rules *lib2.Rules, list string, seed int64) (*lib2.Table, error) {
+	names := []string{}
+	strategies := []lib2.Strategy{}
+	for i, spec := range strings.Split(list, ",") {
+		name, strategy, _ := strings.Cut(strings.TrimSpace(spec), ":")
+		names = append(names, name)
+		if strategy == "" || strategy == lib2.Manual {
+			strategies = append(strategies, nil)
+			continue
+		}
+		s, err := lib2.StrategyByName(strategy, seed+int64(i))
+		if err != nil {
+			return nil, err
+		}
+		strategies = append(strategies, s)
+	}
+	return lib2.NewTableWithRules(rules, names, strategies)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"nmbr9/lib2"
+	"os"
+	"strings"
+)
+
+// nmbr9 tournament -games 30 -strategies lookahead:2,lookahead:3,lookahead:3:1 -ratings ratings.json
+func runTournament(args []string) {
+	cfg := lib2.DefaultTournamentConfig()
+	fs := flag.NewFlagSet("tournament", flag.ExitOnError)
+	fs.IntVar(&cfg.Games, "games", cfg.Games, "number of decks every strategy plays")
+	fs.Int64Var(&cfg.Seed, "seed", cfg.Seed, "seed of the first deck, deck i uses seed+i")
+	fs.IntVar(&cfg.SeenLimit, "copies", cfg.SeenLimit, "copies of each number in the deck")
+	strategies := fs.String("strategies", strings.Join(cfg.Strategies, ","), "comma separated strategies: "+lib2.StrategyNames)
+	ratingsPath := fs.String("ratings", "ratings.json", "rating table kept across tournaments, empty to skip")
+	weightsPath := fs.String("weights", "", weightsUsage)
+	fs.Parse(args)
+	cfg.Strategies = strings.Split(*strategies, ",")
+	weights, err := loadWeights(*weightsPath)
+	if err != nil {
+		fmt.Printf("error loading weights: %v\n", err)
+		return
+	}
+	cfg.Weights = weights
+
+	res, err := lib2.RunTournament(cfg)
+	if err != nil {
+		fmt.Printf("error running tournament: %v\n", err)
+		return
+	}
+	res.Print(os.Stdout)
+	if *ratingsPath == "" {
+		return
+	}
+	ratings, err := lib2.LoadRatings(*ratingsPath)
+	if err != nil {
+		fmt.Printf("error loading ratings: %v\n", err)
+		return
+	}
+	ratings.Update(res)
+	if err := ratings.Save(*ratingsPath); err != nil {
+		fmt.Printf("error saving ratings: %v\n", err)
+		return
+	}
+	fmt.Println()
+	ratings.Print(os.Stdout)
+}
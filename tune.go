@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"nmbr9/lib2"
+	"os"
+)
+
+// nmbr9 tune -generations 20 -population 8 -games 10 -out weights.json
+func runTune(args []string) {
+	cfg := lib2.DefaultTuneConfig()
+	fs := flag.NewFlagSet("tune", flag.ExitOnError)
+	fs.IntVar(&cfg.Generations, "generations", cfg.Generations, "generations to run in total, counting resumed ones")
+	fs.IntVar(&cfg.Population, "population", cfg.Population, "weight sets per generation")
+	fs.IntVar(&cfg.Games, "games", cfg.Games, "decks every weight set plays, the same decks every generation")
+	fs.Int64Var(&cfg.Seed, "seed", cfg.Seed, "seed of the decks and of the mutations")
+	fs.Float64Var(&cfg.Sigma, "sigma", cfg.Sigma, "mutation size relative to each weight")
+	fs.StringVar(&cfg.Strategy, "strategy", cfg.Strategy, "strategy the weights are tuned for")
+	fs.StringVar(&cfg.Out, "out", cfg.Out, "file the best weights are written to")
+	fs.StringVar(&cfg.Checkpoint, "checkpoint", cfg.Checkpoint, "file to resume from and save progress to, empty to skip")
+	fs.Parse(args)
+
+	state, err := lib2.Tune(cfg, os.Stdout)
+	if err != nil {
+		fmt.Printf("error tuning: %v\n", err)
+		return
+	}
+	fmt.Printf("best mean score %.2f with %+v, written to %v\n", state.BestScore, state.Best, cfg.Out)
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"nmbr9/lib2"
+)
+
+// "" means no evaluation, "default" the built-in weights, anything else a weights file
+func loadWeights(path string) (*lib2.Weights, error) {
+	switch path {
+	case "":
+		return nil, nil
+	case "default":
+		w := lib2.DefaultWeights()
+		return &w, nil
+	}
+	w, err := lib2.LoadWeights(path)
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+const weightsUsage = "evaluate positions where the search stops: default or a weights file"
+
+const endgameUsage = "search exactly once this many cards or fewer are left, 0 to never"